@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"time"
 
 	slogctx "github.com/veqryn/slog-context"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -36,6 +37,8 @@ type config struct {
 	AppendToAttributes AppendToAttributes
 	ErrorToLevel       ErrorToLevel
 	DefaultLevel       slog.Level
+	WatchdogThreshold  time.Duration
+	WatchdogInterval   time.Duration
 	role               string
 	Logger             Logger
 }
@@ -233,3 +236,22 @@ type interceptorDefaultLevelOption struct {
 func (o interceptorDefaultLevelOption) apply(c *config) {
 	c.DefaultLevel = o.level
 }
+
+// WithWatchdog returns an Option to log a warning for each call that is still
+// being handled after the threshold, so that hung or stuck handlers can be seen
+// before they finish (or if they never finish). If interval is greater than
+// zero, the warning is repeated at that interval until the call finishes.
+// Only used by the server interceptors.
+func WithWatchdog(threshold, interval time.Duration) Option {
+	return interceptorWatchdogOption{threshold: threshold, interval: interval}
+}
+
+type interceptorWatchdogOption struct {
+	threshold time.Duration
+	interval  time.Duration
+}
+
+func (o interceptorWatchdogOption) apply(c *config) {
+	c.WatchdogThreshold = o.threshold
+	c.WatchdogInterval = o.interval
+}
@@ -263,7 +263,10 @@ func SlogUnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 
 		// Call the next interceptor or the actual handler
 		before := time.Now()
+		stopWatchdog := cfg.startWatchdog(ctx, role, call, pr, before)
+		defer stopWatchdog() // In case the handler panics
 		resp, err := handler(ctx, req)
+		stopWatchdog()
 
 		// Log the response
 		respPayload := Payload{Payload: resp}
@@ -405,8 +408,11 @@ func SlogStreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 		}
 
 		before := time.Now()
+		stopWatchdog := cfg.startWatchdog(ss.Context(), role, call, pr, before)
+		defer stopWatchdog() // In case the handler panics
 		wrapped := wrapServerStream(ss, cfg, role, before, call, pr)
 		err := handler(srv, wrapped)
+		stopWatchdog()
 
 		// If server streaming, there will be many sent/response payloads, so SendMsg will log them all.
 		// Otherwise, there will be only one or zero payloads sent, and possibly an error sent separately.
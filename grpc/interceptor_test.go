@@ -948,6 +948,59 @@ func TestBidirectionalStreaming(t *testing.T) {
 	}
 }
 
+func TestWatchdog(t *testing.T) {
+	serverLogger := &test.Handler{}
+	ctx := slogctx.NewCtx(context.Background(), slog.New(slogctx.NewHandler(serverLogger, nil)))
+	ctx = slogctx.Prepend(ctx, "user", "bob")
+
+	warned := make(chan struct{}, 100)
+	interceptor := SlogUnaryServerInterceptor(
+		WithAppendToAttributes(testFewAppendToAttributes.appendToAttrs),
+		WithWatchdog(5*time.Millisecond, 50*time.Millisecond),
+		WithLogger(func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+			LoggerDefault(ctx, level, msg, attrs...)
+			if msg == "rpcInFlight" {
+				warned <- struct{}{}
+			}
+		}),
+	)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/com.github.veqryn.slogcontext.grpc.test.Test/Unary"}
+	_, err := interceptor(ctx, &protogen.TestReq{Name: "clientRequest"}, info, func(ctx context.Context, req any) (any, error) {
+		// Block until the warning has repeated at least once
+		for i := 0; i < 2; i++ {
+			select {
+			case <-warned:
+			case <-time.After(5 * time.Second):
+				t.Error("Expected repeated in-flight warnings")
+			}
+		}
+		return &protogen.TestResp{Name: "serverResponse"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if len(warned) != 0 {
+		t.Error("Expected no warnings after the call finished; Got:", len(warned))
+	}
+
+	serverJson, err := serverLogger.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverExpected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"rpcReq","user":"bob","role":"server","stream_server":false,"stream_client":false,"req":{"name":"clientRequest"}}
+{"time":"2023-09-29T13:00:59Z","level":"WARN","msg":"rpcInFlight","user":"bob","role":"server","stream_server":false,"stream_client":false}
+{"time":"2023-09-29T13:00:59Z","level":"WARN","msg":"rpcInFlight","user":"bob","role":"server","stream_server":false,"stream_client":false}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"rpcResp","user":"bob","code_name":"OK","code":0,"role":"server","stream_server":false,"stream_client":false,"resp":{"name":"serverResponse"}}
+`
+	if string(serverJson) != serverExpected {
+		t.Error("Expected:", serverExpected, "\nGot:", string(serverJson))
+	}
+}
+
 var _ protogen.TestServer = &server{}
 
 type server struct {
@@ -3,6 +3,7 @@ package sloggrpc
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -140,3 +141,52 @@ func (c *config) logStreamRecv(ctx context.Context, role Role, call Call, stream
 
 	c.Logger(ctx, level, "rpcStreamRecv", attrs...)
 }
+
+// startWatchdog starts a timer that logs an "rpcInFlight" warning once the
+// call has been running past the threshold. It returns a function to stop
+// the timer, which is safe to call more than once, and guarantees nothing is
+// logged after it returns. The warning is logged without holding the lock, so
+// that a slow log handler does not block stopping the timer, other than to
+// wait for a warning already being logged.
+func (c *config) startWatchdog(ctx context.Context, role Role, call Call, peer Peer, before time.Time) func() {
+	if c.WatchdogThreshold <= 0 {
+		return func() {}
+	}
+
+	var mu sync.Mutex
+	var stopped bool
+	var running sync.WaitGroup
+	var timer *time.Timer
+
+	// Hold the lock until the timer is assigned, in case it fires immediately
+	mu.Lock()
+	defer mu.Unlock()
+	timer = time.AfterFunc(c.WatchdogThreshold, func() {
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			return
+		}
+		running.Add(1)
+		mu.Unlock()
+
+		attrs := c.appendCommon(make([]slog.Attr, 0, 10), role, call, peer)
+		attrs = c.appendDurationElapsed(attrs, time.Since(before))
+		c.Logger(ctx, slog.LevelWarn, "rpcInFlight", attrs...)
+
+		mu.Lock()
+		defer mu.Unlock()
+		running.Done()
+		if !stopped && c.WatchdogInterval > 0 {
+			timer.Reset(c.WatchdogInterval)
+		}
+	})
+
+	return func() {
+		mu.Lock()
+		stopped = true
+		timer.Stop()
+		mu.Unlock()
+		running.Wait()
+	}
+}
@@ -81,7 +81,16 @@ func NewHandler(next slog.Handler, opts *HandlerOptions) *Handler {
 
 // Enabled reports whether the next handler handles records at the given level.
 // The handler ignores records whose level is lower.
+// If the context has a level set by WithLevelOverride, that level is used
+// instead. If the context has a minimum level set by WithMinLevel, records
+// below it are also ignored.
 func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if lvl := LevelOverrideFromCtx(ctx); lvl != nil {
+		return level >= lvl.Level()
+	}
+	if lvl := MinLevelFromCtx(ctx); lvl != nil && level < lvl.Level() {
+		return false
+	}
 	return h.next.Enabled(ctx, level)
 }
 
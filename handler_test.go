@@ -153,3 +153,107 @@ func TestHandlerMultipleAttrExtractor(t *testing.T) {
 		t.Errorf("Expected:\n%s\nGot:\n%s\n", expectedText, string(b))
 	}
 }
+
+func TestHandlerContextLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		base     slog.Level
+		expected string
+	}{
+		{
+			base: slog.LevelInfo,
+			expected: `level=INFO msg=info
+level=WARN msg=warn
+level=ERROR msg=error
+level=INFO msg=info
+level=WARN msg=warn
+level=ERROR msg=error
+level=WARN msg=warn
+level=ERROR msg=error
+level=INFO msg=info
+level=WARN msg=warn
+level=ERROR msg=error
+`,
+		},
+		{
+			// A minimum level below the handler's level does not turn on more logs
+			base: slog.LevelError,
+			expected: `level=ERROR msg=error
+level=ERROR msg=error
+level=ERROR msg=error
+level=ERROR msg=error
+`,
+		},
+	} {
+		buf := &strings.Builder{}
+		l := slog.New(NewHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{
+			Level: tc.base,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}), nil))
+
+		ctx := context.Background()
+		debugCtx := WithMinLevel(ctx, slog.LevelDebug)
+		quietCtx := WithMinLevel(debugCtx, slog.LevelWarn)
+		resetCtx := WithMinLevel(quietCtx, nil)
+
+		for _, c := range []context.Context{ctx, debugCtx, quietCtx, resetCtx} {
+			l.DebugContext(c, "debug")
+			l.InfoContext(c, "info")
+			l.WarnContext(c, "warn")
+			l.ErrorContext(c, "error")
+		}
+
+		if buf.String() != tc.expected {
+			t.Error("Base:", tc.base, "Expected:\n", tc.expected, "\nGot:\n", buf.String())
+		}
+
+		if MinLevelFromCtx(ctx) != nil || MinLevelFromCtx(quietCtx).Level() != slog.LevelWarn || MinLevelFromCtx(resetCtx) != nil {
+			t.Error("Unexpected levels from context")
+		}
+	}
+}
+
+func TestHandlerLevelOverride(t *testing.T) {
+	t.Parallel()
+
+	buf := &strings.Builder{}
+	l := slog.New(NewHandler(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelError,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}), nil))
+
+	ctx := context.Background()
+	debugCtx := WithLevelOverride(ctx, slog.LevelDebug)
+	resetCtx := WithLevelOverride(debugCtx, nil)
+
+	for _, c := range []context.Context{ctx, debugCtx, resetCtx} {
+		l.DebugContext(c, "debug")
+		l.WarnContext(c, "warn")
+		l.ErrorContext(c, "error")
+	}
+
+	expected := `level=ERROR msg=error
+level=DEBUG msg=debug
+level=WARN msg=warn
+level=ERROR msg=error
+level=ERROR msg=error
+`
+	if buf.String() != expected {
+		t.Error("Expected:\n", expected, "\nGot:\n", buf.String())
+	}
+
+	if LevelOverrideFromCtx(ctx) != nil || LevelOverrideFromCtx(debugCtx).Level() != slog.LevelDebug || LevelOverrideFromCtx(resetCtx) != nil {
+		t.Error("Unexpected levels from context")
+	}
+}
@@ -0,0 +1,213 @@
+package sloghttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"strings"
+)
+
+// BodyRedactor returns a redacted copy of a captured request or response body.
+// It may return nil to leave the body out of the log line entirely.
+type BodyRedactor func(contentType string, body []byte) []byte
+
+// DefaultBodyContentTypes are the content types of bodies that are captured,
+// unless WithBodyContentTypes is used. JSON types with a "+json" suffix,
+// such as "application/problem+json", are included with "application/json".
+var DefaultBodyContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+	"text/",
+}
+
+// WithRequestBody returns an Option to log up to maxBytes of the request body,
+// as "body" inside the "req" group. JSON bodies are embedded as json, and a
+// "body_truncated" attribute is added if the body was longer than maxBytes.
+// Only the part of the body read by the handler can be captured.
+// Only used by Logger.
+func WithRequestBody(maxBytes int) Option {
+	return requestBodyOption{maxBytes: maxBytes}
+}
+
+type requestBodyOption struct {
+	maxBytes int
+}
+
+func (o requestBodyOption) apply(c *config) {
+	c.Capture.ReqBodyLimit = o.maxBytes
+}
+
+// WithResponseBody returns an Option to log up to maxBytes of the response body,
+// as "body" inside the "resp" group. JSON bodies are embedded as json, and a
+// "body_truncated" attribute is added if the body was longer than maxBytes.
+// Only used by Logger.
+func WithResponseBody(maxBytes int) Option {
+	return responseBodyOption{maxBytes: maxBytes}
+}
+
+type responseBodyOption struct {
+	maxBytes int
+}
+
+func (o responseBodyOption) apply(c *config) {
+	c.Capture.RespBodyLimit = o.maxBytes
+}
+
+// WithBodyContentTypes returns an Option to replace the DefaultBodyContentTypes.
+// Entries ending in "/" match all subtypes, such as "text/".
+// Only used by Logger.
+func WithBodyContentTypes(contentTypes ...string) Option {
+	return bodyContentTypesOption{contentTypes: contentTypes}
+}
+
+type bodyContentTypesOption struct {
+	contentTypes []string
+}
+
+func (o bodyContentTypesOption) apply(c *config) {
+	c.Capture.BodyContentTypes = o.contentTypes
+}
+
+// WithBodyRedactor returns an Option to redact captured request and response
+// bodies before they are logged, such as BodyRedactorJSONFields.
+// Only used by Logger.
+func WithBodyRedactor(f BodyRedactor) Option {
+	return bodyRedactorOption{f: f}
+}
+
+type bodyRedactorOption struct {
+	f BodyRedactor
+}
+
+func (o bodyRedactorOption) apply(c *config) {
+	c.Capture.BodyRedactor = o.f
+}
+
+// BodyRedactorJSONFields returns a BodyRedactor that replaces the values of
+// the given fields (case-insensitive, at any depth) in JSON bodies with
+// "[REDACTED]". JSON bodies that can not be parsed, such as truncated ones,
+// are left out of the log line. Other content types are not changed.
+func BodyRedactorJSONFields(fields ...string) BodyRedactor {
+	redact := lowerSet(fields)
+	return func(contentType string, body []byte) []byte {
+		if !isJSON(contentType) {
+			return body
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil
+		}
+		b, err := json.Marshal(redactJSON(v, redact))
+		if err != nil {
+			return nil
+		}
+		return b
+	}
+}
+
+// redactJSON walks the decoded json, replacing the values of redacted fields
+func redactJSON(v any, redact map[string]struct{}) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			if _, ok := redact[strings.ToLower(k)]; ok {
+				x[k] = "[REDACTED]"
+			} else {
+				x[k] = redactJSON(val, redact)
+			}
+		}
+	case []any:
+		for i, val := range x {
+			x[i] = redactJSON(val, redact)
+		}
+	}
+	return v
+}
+
+// bodyBuffer keeps up to limit bytes written to it, and records if there was more.
+// Writes never fail, so that it can be used with io.TeeReader.
+type bodyBuffer struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func newBodyBuffer(limit int) *bodyBuffer {
+	return &bodyBuffer{limit: limit}
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	keep := p
+	if room := b.limit - len(b.buf); len(keep) > room {
+		b.truncated = true
+		keep = keep[:room]
+	}
+	b.buf = append(b.buf, keep...)
+	return len(p), nil
+}
+
+// room returns how many more bytes will be kept
+func (b *bodyBuffer) room() int {
+	return b.limit - len(b.buf)
+}
+
+// captureBody returns true if bodies of the content type should be captured
+func (c *captureConfig) captureBody(contentType string) bool {
+	mediaType := mediaType(contentType)
+	if mediaType == "" {
+		return false
+	}
+	for _, ct := range c.BodyContentTypes {
+		if strings.HasSuffix(ct, "/") && strings.HasPrefix(mediaType, ct) {
+			return true
+		}
+		if mediaType == ct || (ct == "application/json" && isJSON(mediaType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendBody adds the "body" and "body_truncated" attributes, if anything was captured.
+func (c *captureConfig) appendBody(attrs []slog.Attr, contentType string, body *bodyBuffer) []slog.Attr {
+	if body == nil || len(body.buf) == 0 || !c.captureBody(contentType) {
+		return attrs
+	}
+
+	b := body.buf
+	if c.BodyRedactor != nil {
+		if b = c.BodyRedactor(contentType, b); b == nil {
+			return attrs
+		}
+	}
+
+	// Embed complete json as json, so that json handlers do not escape it as a string
+	if isJSON(contentType) && !body.truncated && json.Valid(b) {
+		attrs = append(attrs, slog.Any("body", json.RawMessage(b)))
+	} else {
+		attrs = append(attrs, slog.String("body", string(b)))
+	}
+	if body.truncated {
+		attrs = append(attrs, slog.Bool("body_truncated", true))
+	}
+	return attrs
+}
+
+// mediaType returns the lowercase media type, without any parameters
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// isJSON returns true for "application/json" and "+json" content types
+func isJSON(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
@@ -0,0 +1,130 @@
+package sloghttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBody(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"id":1,"password":"secret"}`))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.Copy(w, strings.NewReader("Hello, World!"))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0, 1, 2})
+		}
+	})
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithRequestBody(16),
+		WithResponseBody(5),
+	)(final)
+
+	// Complete json request body is embedded as json, truncated json response is a string
+	req := httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"name":"bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	// Truncated form request body, and truncated text response through ReadFrom
+	req = httptest.NewRequest(http.MethodPost, "/text", strings.NewReader("name=bob&password=secret"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	// Content types not in the allowlist are not captured
+	req = httptest.NewRequest(http.MethodPost, "/binary", strings.NewReader("data"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	// Redacted json response body
+	handler = Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithResponseBody(1024),
+		WithBodyRedactor(BodyRedactorJSONFields("Password")),
+	)(final)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/json", nil).WithContext(ctx))
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/json","req":{"body":{"name":"bob"}},"resp":{"body":"{\"id\"","body_truncated":true}}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/text","req":{"body":"name=bob&passwor","body_truncated":true},"resp":{"body":"Hello","body_truncated":true}}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/binary"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/json","resp":{"body":{"id":1,"password":"[REDACTED]"}}}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestBodyBuffer(t *testing.T) {
+	b := newBodyBuffer(4)
+	for _, s := range []string{"ab", "cd", "ef"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatal("Expected writes to always succeed; Got:", n, err)
+		}
+	}
+	if string(b.buf) != "abcd" || !b.truncated {
+		t.Error("Expected abcd and truncated; Got:", string(b.buf), b.truncated)
+	}
+}
+
+// readerFromRecorder records the reader passed to ReadFrom
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	src io.Reader
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.src = src
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestBodyReadFrom(t *testing.T) {
+	cfg := newConfig([]Option{WithResponseBody(5)}, roleServer)
+
+	for _, tc := range []struct {
+		contentType string
+		capture     string
+	}{
+		{contentType: "application/octet-stream", capture: ""},
+		{contentType: "text/plain", capture: "Hello"},
+	} {
+		rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+		w := newResponseWriter(rec)
+		w.capture = newBodyBuffer(cfg.Capture.RespBodyLimit)
+		w.captureType = cfg.Capture.captureBody
+		w.Header().Set("Content-Type", tc.contentType)
+
+		src := strings.NewReader("Hello, World!")
+		n, err := w.ReadFrom(src)
+		if n != 13 || err != nil || rec.Body.String() != "Hello, World!" {
+			t.Fatal("Unexpected ReadFrom result:", n, err, rec.Body.String())
+		}
+
+		// The original reader reaches the wrapped ReadFrom, so it can use sendfile
+		if rec.src != src {
+			t.Errorf("%s: Expected the original reader; Got: %T", tc.contentType, rec.src)
+		}
+		if tc.capture == "" {
+			if w.capture != nil {
+				t.Errorf("%s: Expected no capture", tc.contentType)
+			}
+		} else if string(w.capture.buf) != tc.capture || !w.capture.truncated {
+			t.Errorf("%s: Expected: %s; Got: %s %v", tc.contentType, tc.capture, w.capture.buf, w.capture.truncated)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package sloghttp
+
+import "log/slog"
+
+// WithCanonical returns an Option to make the request log line a "canonical"
+// wide event: the single log line that describes the whole request, with the
+// message "httpCanonical" instead of "httpResp". Like the normal request log
+// line, it includes all attributes added by sloghttp.With, the counters added
+// by sloghttp.Count, the status, duration, and route.
+//
+// The Logger's other per-request lines are folded into it: the
+// "httpStreamStart" and "httpStreamProgress" lines of WithStreaming are not
+// logged, and a panic recovered by sloghttp.Recover adds "panic" and "stack"
+// attributes to the line (logged at least at error level) instead of an
+// "httpPanic" line. The "httpInFlight" warnings of WithWatchdog are still
+// logged, because they are exceptional, and a request that never finishes
+// would otherwise not be logged at all.
+// Use it with WithSuppressBelow to also quiet the other middlewares and the
+// handler, making it the only line logged for most requests.
+// Only used by Logger.
+func WithCanonical() Option {
+	return canonicalOption{}
+}
+
+type canonicalOption struct{}
+
+func (o canonicalOption) apply(c *config) {
+	c.Canonical = true
+}
+
+// WithSuppressBelow returns an Option to suppress all log lines below the level
+// that use the request context, such as the per-request info lines of other
+// middlewares, or of the request handler. The request log line of the Logger
+// is not suppressed. Requires the slogctx.Handler (see slogctx.WithMinLevel).
+// It can only make a request quieter: the level of the final handler still
+// applies, so WithSuppressBelow(slog.LevelDebug) does not turn on debug lines.
+// Ex: WithSuppressBelow(slog.LevelWarn)
+// Only used by Logger.
+func WithSuppressBelow(level slog.Leveler) Option {
+	return suppressBelowOption{level: level}
+}
+
+type suppressBelowOption struct {
+	level slog.Leveler
+}
+
+func (o suppressBelowOption) apply(c *config) {
+	c.SuppressBelow = o.level
+}
+
+// foldPanic records the panic attributes to be logged on the canonical request
+// log line. It returns false if there is no canonical Logger to log them.
+func (s *requestState) foldPanic(attrs []slog.Attr) bool {
+	if s == nil || !s.canonical {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panic = attrs
+	return true
+}
+
+// panicAttrs returns the panic attributes folded by foldPanic
+func (s *requestState) panicAttrs() []slog.Attr {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.panic
+}
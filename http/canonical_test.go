@@ -0,0 +1,111 @@
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func TestCanonical(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	// A noisy middleware that logs every request at info
+	noisy := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slog.InfoContext(r.Context(), "noisy middleware")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	mux := http.NewServeMux()
+	HandleFunc(mux, "GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		With(r.Context(), "user", "bob")
+		Count(r.Context(), "db_queries", 1)
+		Count(r.Context(), "cache_misses", 1)
+		Count(r.Context(), "db_queries", 2)
+		slogctx.Info(r.Context(), "suppressed")
+		slogctx.Warn(r.Context(), "not suppressed")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithCanonical(),
+		WithSuppressBelow(slog.LevelWarn),
+	)(noisy(mux))
+
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slogctx.FromCtx(ctx))
+	req := httptest.NewRequest(http.MethodGet, "/orders/123", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"WARN","msg":"not suppressed","http_route":"GET /orders/{id}","http_path_values":{"id":"123"},"user":"bob"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpCanonical","http_route":"GET /orders/{id}","http_path_values":{"id":"123"},"user":"bob","http_method":"GET","http_path":"/orders/123","http_status":202,"counters":{"db_queries":3,"cache_misses":1}}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+
+	// Count is a no-op outside of the Logger
+	Count(ctx, "ignored", 1)
+}
+
+func TestCanonicalFolded(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	// Slow, streaming, and panicking: each would log its own line without WithCanonical,
+	// but only the in-flight warning is still logged separately
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		panickingHandler(w, r)
+	})
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithCanonical(),
+		WithWatchdog(time.Millisecond, 0),
+		WithStreaming(time.Millisecond),
+	)(Recover(nil)(final))
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	if len(tester.Records) != 2 {
+		t.Fatal("Expected 2 log lines; Got:", len(tester.Records))
+	}
+	if warn := tester.Records[0]; warn.Level != slog.LevelWarn || warn.Message != "httpInFlight" {
+		t.Error("Unexpected in-flight log line:", warn)
+	}
+	rec := tester.Records[1]
+	if rec.Level != slog.LevelError || rec.Message != "httpCanonical" {
+		t.Error("Unexpected canonical log line:", rec)
+	}
+
+	var attrs []string
+	var stack []string
+	rec.Attrs(func(a slog.Attr) bool {
+		if a.Key == "stack" {
+			stack = a.Value.Any().([]string)
+		} else {
+			attrs = append(attrs, a.String())
+		}
+		return true
+	})
+	if got := strings.Join(attrs, " "); got != "user=bob http_method=GET http_path=/stream http_status=200 flushes=1 panic=boom" {
+		t.Error("Unexpected canonical attributes:", got)
+	}
+	if len(stack) == 0 {
+		t.Error("Expected the panic stack on the canonical log line")
+	}
+}
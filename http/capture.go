@@ -0,0 +1,254 @@
+package sloghttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Redactor replaces the value of a redacted header, query parameter, or cookie
+type Redactor func(value string) string
+
+// RedactorDefault replaces all values with "[REDACTED]"
+func RedactorDefault(string) string {
+	return "[REDACTED]"
+}
+
+// RedactorHash replaces values with a truncated sha256 hash, such as
+// "sha256:2cf24dba5fb0a30e", so that equal values can still be correlated.
+// Do not use it for low-entropy values (such as short passwords or pins),
+// because an unsalted hash of those can be reversed by brute force.
+func RedactorHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// DefaultRedactedNames are the header, query parameter, and cookie names
+// (case-insensitive) whose values are redacted, unless WithRedactedNames is used.
+var DefaultRedactedNames = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"token",
+	"access_token",
+	"refresh_token",
+	"password",
+	DefaultForceDebugHeader, // Its tokens can be reused until they expire
+}
+
+// captureConfig holds the allowlists of what to capture on the request log line
+type captureConfig struct {
+	ReqHeaders  []string
+	RespHeaders []string
+	QueryParams []string
+	Cookies     []string
+	Redacted    map[string]struct{} // lowercase names
+	Redactor    Redactor
+
+	ReqBodyLimit     int
+	RespBodyLimit    int
+	BodyContentTypes []string
+	BodyRedactor     BodyRedactor
+}
+
+// WithRequestHeaders returns an Option to log the allowlisted request headers,
+// in a "headers" group inside the "req" group. Use "*" to log all headers.
+// Only used by Logger.
+func WithRequestHeaders(names ...string) Option {
+	return requestHeadersOption{names: canonicalHeaders(names)}
+}
+
+type requestHeadersOption struct {
+	names []string
+}
+
+func (o requestHeadersOption) apply(c *config) {
+	c.Capture.ReqHeaders = append(c.Capture.ReqHeaders, o.names...)
+}
+
+// WithResponseHeaders returns an Option to log the allowlisted response headers,
+// in a "headers" group inside the "resp" group. Use "*" to log all headers.
+// Only used by Logger.
+func WithResponseHeaders(names ...string) Option {
+	return responseHeadersOption{names: canonicalHeaders(names)}
+}
+
+type responseHeadersOption struct {
+	names []string
+}
+
+func (o responseHeadersOption) apply(c *config) {
+	c.Capture.RespHeaders = append(c.Capture.RespHeaders, o.names...)
+}
+
+// WithQueryParams returns an Option to log the allowlisted url query parameters,
+// in a "query" group inside the "req" group. Use "*" to log all parameters.
+// Only used by Logger.
+func WithQueryParams(names ...string) Option {
+	return queryParamsOption{names: names}
+}
+
+type queryParamsOption struct {
+	names []string
+}
+
+func (o queryParamsOption) apply(c *config) {
+	c.Capture.QueryParams = append(c.Capture.QueryParams, o.names...)
+}
+
+// WithCookies returns an Option to log the allowlisted request cookies,
+// in a "cookies" group inside the "req" group. Use "*" to log all cookies.
+// Only used by Logger.
+func WithCookies(names ...string) Option {
+	return cookiesOption{names: names}
+}
+
+type cookiesOption struct {
+	names []string
+}
+
+func (o cookiesOption) apply(c *config) {
+	c.Capture.Cookies = append(c.Capture.Cookies, o.names...)
+}
+
+// WithRedactedNames returns an Option to replace the DefaultRedactedNames.
+// The values of any captured header, query parameter, or cookie with one of
+// these names (case-insensitive) are replaced by the Redactor.
+func WithRedactedNames(names ...string) Option {
+	return redactedNamesOption{names: names}
+}
+
+type redactedNamesOption struct {
+	names []string
+}
+
+func (o redactedNamesOption) apply(c *config) {
+	c.Capture.Redacted = lowerSet(o.names)
+}
+
+// WithRedactor returns an Option to use the redacting function.
+// If not set, RedactorDefault is used.
+func WithRedactor(f Redactor) Option {
+	return redactorOption{f: f}
+}
+
+type redactorOption struct {
+	f Redactor
+}
+
+func (o redactorOption) apply(c *config) {
+	if o.f != nil {
+		c.Capture.Redactor = o.f
+	}
+}
+
+// newCaptureConfig returns a captureConfig with the default redaction
+func newCaptureConfig() captureConfig {
+	return captureConfig{
+		Redacted:         lowerSet(DefaultRedactedNames),
+		Redactor:         RedactorDefault,
+		BodyContentTypes: DefaultBodyContentTypes,
+	}
+}
+
+// appendRequestCapture adds the "req" group with the captured headers, query
+// parameters, cookies and body, if any were found.
+func (c *config) appendRequestCapture(attrs []slog.Attr, r *http.Request, body *bodyBuffer) []slog.Attr {
+	group := make([]slog.Attr, 0, 5)
+	if len(c.Capture.ReqHeaders) > 0 {
+		group = appendGroup(group, "headers", c.Capture.valuesAttrs(r.Header, c.Capture.ReqHeaders))
+	}
+	if len(c.Capture.QueryParams) > 0 {
+		group = appendGroup(group, "query", c.Capture.valuesAttrs(r.URL.Query(), c.Capture.QueryParams))
+	}
+	if len(c.Capture.Cookies) > 0 {
+		cookies := make(map[string][]string)
+		for _, cookie := range r.Cookies() {
+			cookies[cookie.Name] = append(cookies[cookie.Name], cookie.Value)
+		}
+		group = appendGroup(group, "cookies", c.Capture.valuesAttrs(cookies, c.Capture.Cookies))
+	}
+	group = c.Capture.appendBody(group, r.Header.Get("Content-Type"), body)
+
+	if len(group) == 0 {
+		return attrs
+	}
+	return c.AppendToAttributes(attrs, slog.Attr{Key: "req", Value: slog.GroupValue(group...)})
+}
+
+// appendResponseCapture adds the "resp" group with the captured headers and
+// body, if any were found.
+func (c *config) appendResponseCapture(attrs []slog.Attr, header http.Header, body *bodyBuffer) []slog.Attr {
+	group := make([]slog.Attr, 0, 3)
+	if len(c.Capture.RespHeaders) > 0 {
+		group = appendGroup(group, "headers", c.Capture.valuesAttrs(header, c.Capture.RespHeaders))
+	}
+	group = c.Capture.appendBody(group, header.Get("Content-Type"), body)
+
+	if len(group) == 0 {
+		return attrs
+	}
+	return c.AppendToAttributes(attrs, slog.Attr{Key: "resp", Value: slog.GroupValue(group...)})
+}
+
+// valuesAttrs returns the allowlisted values as attributes, in the order of the
+// allowlist, or sorted if all are allowed. Multiple values are joined by ", ".
+func (c *captureConfig) valuesAttrs(values map[string][]string, allow []string) []slog.Attr {
+	names := allow
+	for _, name := range allow {
+		if name == "*" {
+			names = make([]string, 0, len(values))
+			for k := range values {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			break
+		}
+	}
+
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		vals, ok := values[name]
+		if !ok {
+			continue
+		}
+		v := strings.Join(vals, ", ")
+		if _, redact := c.Redacted[strings.ToLower(name)]; redact {
+			v = c.Redactor(v)
+		}
+		attrs = append(attrs, slog.String(name, v))
+	}
+	return attrs
+}
+
+// appendGroup adds a group of attributes, if it is not empty
+func appendGroup(attrs []slog.Attr, key string, group []slog.Attr) []slog.Attr {
+	if len(group) == 0 {
+		return attrs
+	}
+	return append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(group...)})
+}
+
+// canonicalHeaders returns the canonical form of the header names
+func canonicalHeaders(names []string) []string {
+	canonical := make([]string, len(names))
+	for i, name := range names {
+		canonical[i] = http.CanonicalHeaderKey(name)
+	}
+	return canonical
+}
+
+// lowerSet returns a set of the lowercase names
+func lowerSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = struct{}{}
+	}
+	return set
+}
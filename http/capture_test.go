@@ -0,0 +1,60 @@
+package sloghttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Internal", "not-logged")
+		_, _ = w.Write([]byte("Hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=shoes&page=2&token=secret&other=skip", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Add("Accept", "text/plain")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Set("X-Other", "not-logged")
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	req.AddCookie(&http.Cookie{Name: "session", Value: "secret"})
+	req.AddCookie(&http.Cookie{Name: "tracking", Value: "not-logged"})
+	req = req.WithContext(ctx)
+
+	// Default redaction
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithRequestHeaders("authorization", "Accept", "X-Missing"),
+		WithResponseHeaders("content-type", "set-cookie"),
+		WithQueryParams("q", "page", "token"),
+		WithCookies("theme", "session"),
+		WithRedactedNames(append(DefaultRedactedNames, "session")...),
+	)(final)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// All allowed, with a hashing redactor
+	handler = Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithQueryParams("*"),
+		WithResponseHeaders("*"),
+		WithRedactor(RedactorHash),
+	)(final)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/search","req":{"headers":{"Authorization":"[REDACTED]","Accept":"text/plain, application/json"},"query":{"q":"shoes","page":"2","token":"[REDACTED]"},"cookies":{"theme":"dark","session":"[REDACTED]"}},"resp":{"headers":{"Content-Type":"text/plain","Set-Cookie":"[REDACTED]"}}}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/search","req":{"query":{"other":"skip","page":"2","q":"shoes","token":"sha256:2bb80d537b1da3e3"}},"resp":{"headers":{"Content-Type":"text/plain","Set-Cookie":"sha256:df856efc041fdfc1","X-Internal":"not-logged"}}}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
@@ -0,0 +1,164 @@
+package sloghttp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// DefaultForwardedHeader is the default header that the trusted proxies set
+// with the client IP.
+const DefaultForwardedHeader = "X-Forwarded-For"
+
+// WithTrustedProxies returns an Option to compute the real client IP from the
+// forwarding header (see WithForwardedHeader), when the request came from one
+// of the trusted proxy networks. The client IP is logged as "client_ip", in
+// addition to the peer address.
+// Ex: WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+// Only used by Logger.
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return trustedProxiesOption{prefixes: prefixes}
+}
+
+type trustedProxiesOption struct {
+	prefixes []netip.Prefix
+}
+
+func (o trustedProxiesOption) apply(c *config) {
+	c.TrustedProxies = append(c.TrustedProxies, o.prefixes...)
+}
+
+// WithForwardedHeader returns an Option to set the one header that the trusted
+// proxies set with the client IP, such as "Forwarded" (RFC 7239), "X-Real-IP",
+// or a CDN specific header. Only this header is read, because a client can send
+// any of the other headers, and a proxy that does not set them passes them on.
+// If not used, DefaultForwardedHeader (X-Forwarded-For) is read.
+// Only used by Logger.
+func WithForwardedHeader(header string) Option {
+	return forwardedHeaderOption{header: header}
+}
+
+type forwardedHeaderOption struct {
+	header string
+}
+
+func (o forwardedHeaderOption) apply(c *config) {
+	c.ForwardedHeader = o.header
+}
+
+// clientIPKey is the context key for the client IP
+type clientIPKey struct{}
+
+// ClientIPFromCtx returns the client IP computed by sloghttp.Logger, or an
+// empty string if there is none. Other middlewares (such as rate limiters) can
+// use it, so that they agree with the access log about who the client is.
+func ClientIPFromCtx(ctx context.Context) string {
+	if ip, ok := ctx.Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return ""
+}
+
+// ClientIP returns the IP of the client that made the request.
+// The forwarding header can be set to anything by the client, so it is only
+// used if the immediate peer (http.Request.RemoteAddr) is in one of the trusted
+// proxy networks, and only the one header that the trusted proxies set is read
+// (if empty, DefaultForwardedHeader). The "Forwarded" header is parsed as
+// RFC 7239, and any other header as a comma separated list of addresses.
+// Lists of addresses are read from right to left, skipping any trusted
+// proxies, so that a client can not spoof its IP by sending its own header.
+// It falls back to the host of the RemoteAddr.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix, header string) string {
+	host, _ := splitHostPort(r.RemoteAddr)
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !trusted(peer, trustedProxies) {
+		return host
+	}
+
+	if header == "" {
+		header = DefaultForwardedHeader
+	}
+	var hops []string
+	if http.CanonicalHeaderKey(header) == "Forwarded" {
+		hops = forwardedFor(r.Header.Values(header))
+	} else {
+		hops = splitList(r.Header.Values(header))
+	}
+	if ip, ok := rightmostUntrusted(hops, trustedProxies); ok {
+		return ip.String()
+	}
+	return host
+}
+
+// rightmostUntrusted returns the last address that is not a trusted proxy,
+// or the first address if they are all trusted. It returns false if the list
+// is empty, or if an address before the client can not be parsed.
+func rightmostUntrusted(hops []string, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	var ip netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		var err error
+		ip, err = parseIP(hops[i])
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if !trusted(ip, trustedProxies) {
+			return ip, true
+		}
+	}
+	return ip, ip.IsValid()
+}
+
+// trusted returns true if the address is in any of the networks
+func trusted(ip netip.Addr, prefixes []netip.Prefix) bool {
+	ip = ip.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the "for" parameters of the RFC 7239 Forwarded headers,
+// such as: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8::17]:4711"
+func forwardedFor(headers []string) []string {
+	var hops []string
+	for _, element := range splitList(headers) {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hops = append(hops, strings.Trim(value, `"`))
+			}
+		}
+	}
+	return hops
+}
+
+// splitList splits comma separated header values into a single list
+func splitList(headers []string) []string {
+	var list []string
+	for _, h := range headers {
+		for _, v := range strings.Split(h, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
+// parseIP parses an IP address that may have a port, or be in brackets,
+// such as "192.0.2.60", "192.0.2.60:80", "[2001:db8::17]:4711", or "2001:db8::17".
+func parseIP(s string) (netip.Addr, error) {
+	if ip, err := netip.ParseAddr(s); err == nil {
+		return ip.Unmap(), nil
+	}
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	}
+	ip, err := netip.ParseAddr(host)
+	return ip.Unmap(), err
+}
@@ -0,0 +1,141 @@
+package sloghttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8:ffff::/48"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     string
+		headers    map[string][]string
+		expected   string
+	}{
+		{
+			name:       "untrusted peer ignores headers",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			expected:   "192.0.2.1",
+		},
+		{
+			name:       "trusted peer without headers",
+			remoteAddr: "10.0.0.1:1234",
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "x-forwarded-for skips trusted proxies from the right",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.9, 203.0.113.7", "10.1.1.1"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "x-forwarded-for all trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.3.3.3, 10.2.2.2"}},
+			expected:   "10.3.3.3",
+		},
+		{
+			name:       "client sent forwarded is ignored when the proxy only sets x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded":       {"for=6.6.6.6"},
+				"X-Real-IP":       {"6.6.6.6"},
+				"X-Forwarded-For": {"203.0.113.7"},
+			},
+			expected: "203.0.113.7",
+		},
+		{
+			name:       "forwarded header, with ipv6 and ports",
+			remoteAddr: "[2001:db8:ffff::1]:443",
+			header:     "Forwarded",
+			headers: map[string][]string{
+				"Forwarded":       {`for=192.0.2.60;proto=http;by=203.0.113.43, For="[2001:db8:cafe::17]:4711"`},
+				"X-Forwarded-For": {"198.51.100.9"},
+			},
+			expected: "2001:db8:cafe::17",
+		},
+		{
+			name:       "unparsable forwarded falls back to the peer",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "forwarded",
+			headers: map[string][]string{
+				"Forwarded":       {"for=unknown"},
+				"X-Forwarded-For": {"203.0.113.8"},
+			},
+			expected: "10.0.0.1",
+		},
+		{
+			name:       "x-real-ip",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Real-IP",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"6.6.6.6"},
+				"X-Real-IP":       {"203.0.113.8"},
+			},
+			expected: "203.0.113.8",
+		},
+		{
+			name:       "ipv4 mapped ipv6 peer",
+			remoteAddr: "[::ffff:10.0.0.1]:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.7:5555"}},
+			expected:   "203.0.113.7",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for k, vals := range tc.headers {
+				for _, v := range vals {
+					r.Header.Add(k, v)
+				}
+			}
+			if got := ClientIP(r, trustedProxies, tc.header); got != tc.expected {
+				t.Error("Expected:", tc.expected, "Got:", got)
+			}
+		})
+	}
+}
+
+func TestLoggerClientIP(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	var fromCtx string
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_path": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithTrustedProxies(netip.MustParsePrefix("192.0.2.0/24")),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromCtx = ClientIPFromCtx(r.Context())
+	}))
+
+	// The client sends its own Forwarded header, through a proxy that only sets X-Forwarded-For
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Forwarded", "for=6.6.6.6")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	if fromCtx != "203.0.113.7" {
+		t.Error("Expected the client IP in the context; Got:", fromCtx)
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","client_ip":"203.0.113.7","peer_host":"192.0.2.1"}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
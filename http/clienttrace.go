@@ -0,0 +1,194 @@
+package sloghttp
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+// ClientTraceMode selects how the connection timings of outbound requests are logged
+type ClientTraceMode int
+
+const (
+	// ClientTraceOff does not trace outbound requests
+	ClientTraceOff ClientTraceMode = iota
+
+	// ClientTraceAttrs adds the connection timings as a "conn" group on the
+	// httpClientResp log line
+	ClientTraceAttrs
+
+	// ClientTraceEvents logs each step of the connection at debug level as it
+	// finishes, in addition to adding the "conn" group on the httpClientResp log line
+	ClientTraceEvents
+)
+
+// WithClientTrace returns an Option to record the connection timings of
+// outbound requests with net/http/httptrace: DNS lookup, TCP connect, TLS
+// handshake, time to first response byte, and whether the connection was reused.
+// Any httptrace.ClientTrace already on the request's context is still called.
+// Only used by Transport.
+func WithClientTrace(mode ClientTraceMode) Option {
+	return clientTraceOption{mode: mode}
+}
+
+type clientTraceOption struct {
+	mode ClientTraceMode
+}
+
+func (o clientTraceOption) apply(c *config) {
+	c.ClientTrace = o.mode
+}
+
+// clientTrace records the timings of a single outbound request.
+// The httptrace hooks may be called concurrently, so all fields are guarded.
+type clientTrace struct {
+	cfg *config
+	ctx context.Context
+
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dns          time.Duration
+	connectStart time.Time
+	connect      time.Duration
+	tlsStart     time.Time
+	tls          time.Duration
+	firstByte    time.Duration
+	gotConn      bool
+	reused       bool
+}
+
+// newClientTrace returns a copy of the request with a httptrace.ClientTrace
+// attached to its context.
+func (c *config) newClientTrace(req *http.Request) (*http.Request, *clientTrace) {
+	ct := &clientTrace{
+		cfg:   c,
+		ctx:   req.Context(),
+		start: time.Now(),
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart:             ct.dnsStartHook,
+		DNSDone:              ct.dnsDoneHook,
+		ConnectStart:         ct.connectStartHook,
+		ConnectDone:          ct.connectDoneHook,
+		TLSHandshakeStart:    ct.tlsStartHook,
+		TLSHandshakeDone:     ct.tlsDoneHook,
+		GotConn:              ct.gotConnHook,
+		GotFirstResponseByte: ct.firstByteHook,
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), ct
+}
+
+func (ct *clientTrace) dnsStartHook(httptrace.DNSStartInfo) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.dnsStart = time.Now()
+}
+
+func (ct *clientTrace) dnsDoneHook(info httptrace.DNSDoneInfo) {
+	ct.mu.Lock()
+	ct.dns = time.Since(ct.dnsStart)
+	elapsed := ct.dns
+	ct.mu.Unlock()
+	ct.event("httpClientDNS", elapsed, info.Err)
+}
+
+func (ct *clientTrace) connectStartHook(string, string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	// Multiple addresses may be dialed in parallel, so time from the first start
+	if ct.connectStart.IsZero() {
+		ct.connectStart = time.Now()
+	}
+}
+
+func (ct *clientTrace) connectDoneHook(_ string, _ string, err error) {
+	ct.mu.Lock()
+	ct.connect = time.Since(ct.connectStart)
+	elapsed := ct.connect
+	ct.mu.Unlock()
+	ct.event("httpClientConnect", elapsed, err)
+}
+
+func (ct *clientTrace) tlsStartHook() {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.tlsStart = time.Now()
+}
+
+func (ct *clientTrace) tlsDoneHook(_ tls.ConnectionState, err error) {
+	ct.mu.Lock()
+	ct.tls = time.Since(ct.tlsStart)
+	elapsed := ct.tls
+	ct.mu.Unlock()
+	ct.event("httpClientTLS", elapsed, err)
+}
+
+func (ct *clientTrace) gotConnHook(info httptrace.GotConnInfo) {
+	ct.mu.Lock()
+	ct.gotConn = true
+	ct.reused = info.Reused
+	elapsed := time.Since(ct.start)
+	ct.mu.Unlock()
+	ct.event("httpClientGotConn", elapsed, nil, slog.Bool("reused", info.Reused))
+}
+
+func (ct *clientTrace) firstByteHook() {
+	ct.mu.Lock()
+	ct.firstByte = time.Since(ct.start)
+	elapsed := ct.firstByte
+	ct.mu.Unlock()
+	ct.event("httpClientFirstByte", elapsed, nil)
+}
+
+// event logs a finished connection step at debug level, if enabled.
+func (ct *clientTrace) event(msg string, elapsed time.Duration, err error, extra ...slog.Attr) {
+	if ct.cfg.ClientTrace != ClientTraceEvents {
+		return
+	}
+	attrs := make([]slog.Attr, 0, 3)
+	attrs = ct.cfg.appendDurationElapsed(attrs, elapsed)
+	for _, a := range extra {
+		attrs = ct.cfg.AppendToAttributes(attrs, a)
+	}
+	if err != nil {
+		attrs = ct.cfg.AppendToAttributes(attrs, slogctx.Err(err))
+	}
+	ct.cfg.LogFunc(ct.ctx, slog.LevelDebug, msg, attrs...)
+}
+
+// attr returns the timings as a "conn" group, leaving out the steps that did
+// not happen (for example, DNS and connect are skipped on reused connections).
+func (ct *clientTrace) attr() slog.Attr {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	// The transport may start dialing a new connection and then use an idle
+	// connection that became available in the meantime. That dial is not part
+	// of this request's timings.
+	dialed := !ct.gotConn || !ct.reused
+
+	attrs := make([]slog.Attr, 0, 5)
+	if dialed && !ct.dnsStart.IsZero() {
+		attrs = append(attrs, slog.Float64("dns_ms", float64(ct.dns)/float64(time.Millisecond)))
+	}
+	if dialed && !ct.connectStart.IsZero() {
+		attrs = append(attrs, slog.Float64("connect_ms", float64(ct.connect)/float64(time.Millisecond)))
+	}
+	if dialed && !ct.tlsStart.IsZero() {
+		attrs = append(attrs, slog.Float64("tls_ms", float64(ct.tls)/float64(time.Millisecond)))
+	}
+	if ct.firstByte > 0 {
+		attrs = append(attrs, slog.Float64("ttfb_ms", float64(ct.firstByte)/float64(time.Millisecond)))
+	}
+	if ct.gotConn {
+		attrs = append(attrs, slog.Bool("reused", ct.reused))
+	}
+	return slog.Attr{Key: "conn", Value: slog.GroupValue(attrs...)}
+}
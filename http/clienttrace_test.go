@@ -0,0 +1,83 @@
+package sloghttp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClientTrace(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Hello"))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var lines []string
+	logFunc := func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+		mu.Lock()
+		defer mu.Unlock()
+		line := level.String() + " " + msg + " user=" + ctx.Value(userKey{}).(string)
+		for _, a := range attrs {
+			if a.Key == "conn" {
+				for _, ga := range a.Value.Group() {
+					line += " conn." + ga.Key
+					if ga.Key == "reused" {
+						line += "=" + ga.Value.String()
+					}
+				}
+				continue
+			}
+			line += " " + a.Key
+		}
+		lines = append(lines, line)
+	}
+
+	client := &http.Client{Transport: Transport(srv.Client().Transport,
+		WithClientTrace(ClientTraceEvents),
+		WithLogFunc(logFunc),
+		WithAppendToAttributes(disableFields{"http_method": {}, "http_host": {}, "http_path": {}}.appendToAttrs),
+	)}
+
+	// An httptrace already on the context must still be called
+	var userGotConn int
+	ctx := context.WithValue(context.Background(), userKey{}, "bob")
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { userGotConn++ },
+	})
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Read the body fully, so the connection is put back in the pool
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	if userGotConn != 2 {
+		t.Error("Expected the existing httptrace to be called twice; Got:", userGotConn)
+	}
+
+	expected := `DEBUG httpClientConnect user=bob ms
+DEBUG httpClientTLS user=bob ms
+DEBUG httpClientGotConn user=bob ms reused
+DEBUG httpClientFirstByte user=bob ms
+INFO httpClientResp user=bob http_status ms conn.connect_ms conn.tls_ms conn.ttfb_ms conn.reused=false
+DEBUG httpClientGotConn user=bob ms reused
+DEBUG httpClientFirstByte user=bob ms
+INFO httpClientResp user=bob http_status ms conn.ttfb_ms conn.reused=true`
+	if got := strings.Join(lines, "\n"); got != expected {
+		t.Error("Expected:\n", expected, "\nGot:\n", got)
+	}
+}
+
+type userKey struct{}
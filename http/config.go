@@ -0,0 +1,342 @@
+package sloghttp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+// RequestFilter is a predicate used to determine whether a given request should
+// be logged. A RequestFilter must return true if the request should be logged.
+// Logger calls it once per request, after the request has been handled, or if
+// the response is streamed (see WithStreaming), when the stream starts, so that
+// all of the stream's log lines are either logged or skipped. Either way, the
+// request has been routed, so the route pattern is available.
+// Transport calls it before sending the request.
+type RequestFilter func(*http.Request) bool
+
+// AppendToAttributes allows customizing the attributes, including disabling some
+type AppendToAttributes func(attrs []slog.Attr, attr slog.Attr) []slog.Attr
+
+// StatusToLevel defines the mapping between an error http status code (4xx and 5xx) to a log level
+type StatusToLevel func(status int) slog.Level
+
+// ErrorToLevel defines the mapping between an outbound request's transport error to a log level
+type ErrorToLevel func(err error) slog.Level
+
+// LogFunc defines the logging function to use for the middleware (same signature as slog.LogAttrs and slogctx.LogAttrs)
+type LogFunc func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+
+// config is a group of options for the request logging middleware.
+type config struct {
+	RequestFilter          RequestFilter
+	AppendToAttributes     AppendToAttributes
+	StatusToLevel          StatusToLevel
+	ErrorToLevel           ErrorToLevel
+	DefaultLevel           slog.Level
+	RouteLevels            map[string]slog.Level
+	RequestIDHeader        string
+	AttrHeaders            map[string]string
+	ClientTrace            ClientTraceMode
+	Capture                captureConfig
+	TrustedProxies         []netip.Prefix
+	ForwardedHeader        string
+	WatchdogThreshold      time.Duration
+	WatchdogInterval       time.Duration
+	Canonical              bool
+	ServerTiming           bool
+	Streaming              bool
+	StreamProgressInterval time.Duration
+	SuppressBelow          slog.Leveler
+	role                   role
+	LogFunc                LogFunc
+}
+
+// role selects the defaults of a config, for either logging inbound requests
+// (Logger) or outbound requests (Transport)
+type role int
+
+const (
+	// roleServer is the role of the Logger middleware, for inbound requests
+	roleServer role = iota
+
+	// roleClient is the role of the Transport, for outbound requests
+	roleClient
+)
+
+// Option applies an option value for a config.
+type Option interface {
+	apply(*config)
+}
+
+// newConfig returns a config configured with all the passed Options.
+func newConfig(opts []Option, r role) *config {
+	c := &config{
+		AppendToAttributes: AppendToAttributesDefault,
+		ErrorToLevel:       ErrorToLevelDefault,
+		LogFunc:            LogFuncDefault,
+		DefaultLevel:       slog.LevelInfo,
+		Capture:            newCaptureConfig(),
+		role:               r,
+	}
+	switch r {
+	case roleServer:
+		c.StatusToLevel = StatusToLevelDefault
+	case roleClient:
+		c.StatusToLevel = StatusToLevelClientDefault
+	}
+
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+// StatusToLevelDefault is the helper mapper that maps http error status codes to log levels.
+// Server errors (5xx) are logged at error level, and client errors (4xx) at warn level.
+func StatusToLevelDefault(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// StatusToLevelClientDefault is the helper mapper that maps http error status codes to log levels for outbound requests.
+// Server errors (5xx), and authentication and rate limiting errors are logged at warn level,
+// while other client errors (4xx) are logged at info level.
+func StatusToLevelClientDefault(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelWarn
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ErrorToLevelDefault is the helper mapper that maps outbound request errors to log levels.
+// Requests canceled by the caller are logged at info level, all others at warn level.
+func ErrorToLevelDefault(err error) slog.Level {
+	if errors.Is(err, context.Canceled) {
+		return slog.LevelInfo
+	}
+	return slog.LevelWarn
+}
+
+// AppendToAttributesAll allows all attributes
+var AppendToAttributesAll AppendToAttributes = func(attrs []slog.Attr, attr slog.Attr) []slog.Attr {
+	return append(attrs, attr)
+}
+
+// AppendToAttributesDefault allows the default attributes
+var AppendToAttributesDefault AppendToAttributes = disableFields{
+	"peer_port": {},
+}.appendToAttrs
+
+type disableFields map[string]struct{}
+
+func (df disableFields) appendToAttrs(attrs []slog.Attr, attr slog.Attr) []slog.Attr {
+	if _, ok := df[attr.Key]; ok {
+		return attrs
+	}
+	return append(attrs, attr)
+}
+
+// WithAppendToAttributes returns an Option to use the appending function
+func WithAppendToAttributes(f AppendToAttributes) Option {
+	return appendToAttributesOption{f: f}
+}
+
+type appendToAttributesOption struct {
+	f AppendToAttributes
+}
+
+func (o appendToAttributesOption) apply(c *config) {
+	if o.f != nil {
+		c.AppendToAttributes = o.f
+	}
+}
+
+// WithRequestFilter returns an Option to use the request filter.
+func WithRequestFilter(f RequestFilter) Option {
+	return requestFilterOption{f: f}
+}
+
+type requestFilterOption struct {
+	f RequestFilter
+}
+
+func (o requestFilterOption) apply(c *config) {
+	if o.f != nil {
+		c.RequestFilter = o.f
+	}
+}
+
+// RequestFilterIgnorePaths returns a RequestFilter that will ignore all
+// requests for the exact url paths given, such as "/healthz" or "/metrics".
+func RequestFilterIgnorePaths(paths ...string) RequestFilter {
+	ignore := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		ignore[p] = struct{}{}
+	}
+	return func(r *http.Request) bool {
+		_, ok := ignore[r.URL.Path]
+		return !ok
+	}
+}
+
+// RequestFilterIgnoreRoutes returns a RequestFilter that will ignore all
+// requests matched by the exact http.ServeMux patterns given,
+// such as "GET /healthz" or "/debug/pprof/".
+// Requires the route to be recorded by sloghttp.Handle, or go 1.23+ and the
+// http.ServeMux to be inside the middleware.
+// It does not apply to Transport, because outbound requests have no route,
+// even when they are sent while handling an inbound request.
+func RequestFilterIgnoreRoutes(patterns ...string) RequestFilter {
+	ignore := make(map[string]struct{}, len(patterns))
+	for _, p := range patterns {
+		ignore[p] = struct{}{}
+	}
+	return func(r *http.Request) bool {
+		route, _ := requestRoute(r)
+		_, ok := ignore[route]
+		return !ok
+	}
+}
+
+// WithStatusToLevel returns an Option to use the status code to level function
+func WithStatusToLevel(f StatusToLevel) Option {
+	return statusToLevelOption{f: f}
+}
+
+type statusToLevelOption struct {
+	f StatusToLevel
+}
+
+func (o statusToLevelOption) apply(c *config) {
+	if o.f != nil {
+		c.StatusToLevel = o.f
+	}
+}
+
+// WithErrorToLevel returns an Option to use the error to level function.
+// Only used by Transport.
+func WithErrorToLevel(f ErrorToLevel) Option {
+	return errorToLevelOption{f: f}
+}
+
+type errorToLevelOption struct {
+	f ErrorToLevel
+}
+
+func (o errorToLevelOption) apply(c *config) {
+	if o.f != nil {
+		c.ErrorToLevel = o.f
+	}
+}
+
+// LogFuncDefault returns slogctx.LogAttrs as the default LogFunc function
+var LogFuncDefault LogFunc = slogctx.LogAttrs
+
+// WithLogFunc returns an Option to use the logging function
+func WithLogFunc(f LogFunc) Option {
+	return logFuncOption{f: f}
+}
+
+type logFuncOption struct {
+	f LogFunc
+}
+
+func (o logFuncOption) apply(c *config) {
+	if o.f != nil {
+		c.LogFunc = o.f
+	}
+}
+
+// WithDefaultLevel returns an Option to set the log level for successful requests (status below 400)
+func WithDefaultLevel(level slog.Level) Option {
+	return defaultLevelOption{level: level}
+}
+
+type defaultLevelOption struct {
+	level slog.Level
+}
+
+func (o defaultLevelOption) apply(c *config) {
+	c.DefaultLevel = o.level
+}
+
+// WithRouteLevels returns an Option to override the default level of successful
+// requests (status below 400) on some routes, such as setting "GET /healthz" to debug level.
+// The keys are matched against the http.ServeMux pattern first, then the url path.
+// Transport only matches the url path, because outbound requests have no route.
+// Error status codes still use the StatusToLevel function.
+func WithRouteLevels(levels map[string]slog.Level) Option {
+	return routeLevelsOption{levels: levels}
+}
+
+type routeLevelsOption struct {
+	levels map[string]slog.Level
+}
+
+func (o routeLevelsOption) apply(c *config) {
+	if len(o.levels) == 0 {
+		return
+	}
+	if c.RouteLevels == nil {
+		c.RouteLevels = make(map[string]slog.Level, len(o.levels))
+	}
+	for k, v := range o.levels {
+		c.RouteLevels[k] = v
+	}
+}
+
+// WithRequestIDHeader returns an Option to send the request ID found in the
+// context (see RequestID and RequestIDFromCtx) to the server in the given header,
+// such as DefaultRequestIDHeader. Only used by Transport.
+func WithRequestIDHeader(header string) Option {
+	return requestIDHeaderOption{header: header}
+}
+
+type requestIDHeaderOption struct {
+	header string
+}
+
+func (o requestIDHeaderOption) apply(c *config) {
+	c.RequestIDHeader = o.header
+}
+
+// WithAttrHeaders returns an Option to send the allowlisted attributes found in
+// the context (added by slogctx.Prepend, slogctx.Append, or sloghttp.With) to
+// the server as headers. The map keys are the attribute keys, and the values
+// are the header names, such as {"tenant_id": "X-Tenant-ID"}.
+// Only used by Transport.
+func WithAttrHeaders(attrToHeader map[string]string) Option {
+	return attrHeadersOption{attrToHeader: attrToHeader}
+}
+
+type attrHeadersOption struct {
+	attrToHeader map[string]string
+}
+
+func (o attrHeadersOption) apply(c *config) {
+	if len(o.attrToHeader) == 0 {
+		return
+	}
+	if c.AttrHeaders == nil {
+		c.AttrHeaders = make(map[string]string, len(o.attrToHeader))
+	}
+	for k, v := range o.attrToHeader {
+		c.AttrHeaders[k] = v
+	}
+}
@@ -1,9 +1,11 @@
 package sloghttp_test
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	slogctx "github.com/veqryn/slog-context"
 	sloghttp "github.com/veqryn/slog-context/http"
@@ -88,3 +90,171 @@ func ExampleAttrCollection() {
 		panic(err)
 	}
 }
+
+func ExampleLogger() {
+	// This is our final api endpoint handler
+	helloUserHandler := func(w http.ResponseWriter, r *http.Request) {
+		// Add the user ID to the request's attribute collection,
+		// so that it is included in the request log line too.
+		id := r.URL.Query().Get("id")
+		ctx := sloghttp.With(r.Context(), "id", id)
+
+		slogctx.Info(ctx, "saying hello...")
+		_, _ = w.Write([]byte("Hello User #" + id))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", helloUserHandler)
+
+	// Wrap our router inside the request logging middleware.
+	// It will log one line per request, after the request has been handled:
+	/*
+		{
+			"time": "2024-04-01T00:06:11Z",
+			"level": "INFO",
+			"msg": "httpResp",
+			"id": "24680",
+			"http_method": "GET",
+			"http_path": "/hello",
+			"http_route": "/hello",
+			"http_status": 200,
+			"req_bytes": 0,
+			"resp_bytes": 17,
+			"ms": 0.052,
+			"peer_host": "127.0.0.1",
+			"user_agent": "curl/8.5.0"
+		}
+	*/
+	handler := sloghttp.Logger(
+		sloghttp.WithDefaultLevel(slog.LevelInfo),
+	)(mux)
+
+	err := http.ListenAndServe(":8080", handler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleRequestID() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		// Will include "request_id", as will the sloghttp.Logger log line
+		slogctx.Info(r.Context(), "saying hello...")
+		_, _ = w.Write([]byte("Hello"))
+	})
+
+	// Logger -> RequestID -> Router.
+	// The request ID is taken from the X-Request-ID header if present and
+	// valid, otherwise it is generated, and it is always echoed in the response.
+	handler := sloghttp.Logger()(
+		sloghttp.RequestID(&sloghttp.RequestIDOptions{
+			Generator: sloghttp.RequestIDGeneratorULID,
+		})(mux),
+	)
+
+	err := http.ListenAndServe(":8080", handler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleForceDebug() {
+	secret := []byte(os.Getenv("DEBUG_LOG_SECRET"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		// Only logged for requests with a valid X-Debug-Log header,
+		// and tagged with "debug_forced=true"
+		slogctx.Debug(r.Context(), "saying hello...")
+		_, _ = w.Write([]byte("Hello"))
+	})
+
+	// Support engineers can send a short-lived token made with:
+	// sloghttp.ForceDebugToken(secret, time.Now().Add(15*time.Minute))
+	handler := sloghttp.Logger()(
+		sloghttp.ForceDebug(&sloghttp.ForceDebugOptions{
+			Verifier: sloghttp.ForceDebugVerifierHMAC(secret, time.Hour),
+		})(mux),
+	)
+
+	err := http.ListenAndServe(":8080", handler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleTransport() {
+	// Wrap the transport of the http client, to log all outbound requests.
+	// The request ID and tenant ID will be forwarded to the server as headers.
+	client := &http.Client{
+		Transport: sloghttp.Transport(http.DefaultTransport,
+			sloghttp.WithRequestIDHeader(sloghttp.DefaultRequestIDHeader),
+			sloghttp.WithAttrHeaders(map[string]string{"tenant_id": "X-Tenant-ID"}),
+			sloghttp.WithClientTrace(sloghttp.ClientTraceAttrs),
+		),
+	}
+
+	ctx := slogctx.Prepend(context.Background(), "tenant_id", "acme")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/hello", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	/*
+		{
+			"time": "2024-04-01T00:06:11Z",
+			"level": "INFO",
+			"msg": "httpClientResp",
+			"tenant_id": "acme",
+			"http_method": "GET",
+			"http_host": "localhost:8080",
+			"http_path": "/hello",
+			"http_status": 200,
+			"ms": 1.267,
+			"conn": {
+				"dns_ms": 0.188,
+				"connect_ms": 0.121,
+				"ttfb_ms": 1.171,
+				"reused": false
+			}
+		}
+	*/
+}
+
+func ExampleRecover() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		sloghttp.With(r.Context(), "id", r.URL.Query().Get("id"))
+		panic("something went wrong")
+	})
+
+	// Logger -> Recover -> Router.
+	// The panic is logged with the "id" attribute and the stack trace,
+	// then a 500 is written, which the Logger then logs as the status.
+	/*
+		{
+			"time": "2024-04-01T00:06:11Z",
+			"level": "ERROR",
+			"msg": "httpPanic",
+			"id": "24680",
+			"panic": "something went wrong",
+			"stack": [
+				"main.main.func1 /app/main.go:14",
+				"net/http.HandlerFunc.ServeHTTP /usr/local/go/src/net/http/server.go:2294"
+			],
+			"http_method": "GET",
+			"http_path": "/hello"
+		}
+	*/
+	handler := sloghttp.Logger()(sloghttp.Recover(nil)(mux))
+
+	err := http.ListenAndServe(":8080", handler)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,131 @@
+package sloghttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"github.com/veqryn/slog-context/propagate"
+)
+
+// DefaultForceDebugHeader is the default http header that forces debug logging for a request.
+const DefaultForceDebugHeader = "X-Debug-Log"
+
+// DefaultKeyForceDebug is the default attribute key added to the log lines of a forced debug request.
+const DefaultKeyForceDebug = "debug_forced"
+
+// ForceDebugVerifier returns true if the header value proves that the
+// request is allowed to turn on debug logging.
+type ForceDebugVerifier func(r *http.Request, value string) bool
+
+// ForceDebugOptions are options for the ForceDebug middleware
+type ForceDebugOptions struct {
+	// Header is the http header that holds the token that forces debug logging.
+	// If left empty, DefaultForceDebugHeader is used.
+	Header string
+
+	// Key is the attribute key added (with the value true) to all log lines
+	// of a forced debug request. If left empty, DefaultKeyForceDebug is used.
+	Key string
+
+	// Level is the minimum log level used for a forced debug request.
+	// If left nil, slog.LevelDebug is used.
+	Level slog.Leveler
+
+	// Verifier checks the header value. It is required, so that arbitrary
+	// clients can not flood the logs. See ForceDebugVerifierHMAC.
+	Verifier ForceDebugVerifier
+}
+
+// ForceDebug returns an http middleware that turns on debug logging for a
+// single request, when the request has a header with a value accepted by the
+// Verifier. The level is set with slogctx.WithLevelOverride, so it requires
+// the slogctx.Handler, and it overrides both the level of the final handler and
+// WithSuppressBelow of an earlier Logger.
+// All log lines of the request are tagged with "debug_forced=true".
+//
+// If the attribute collection has already been initialized by an earlier
+// middleware (such as sloghttp.AttrCollection or sloghttp.Logger), the tag is
+// added with sloghttp.With, so that it is visible to those earlier middlewares
+// too. Otherwise, it is added with slogctx.Prepend.
+//
+// It panics if opts or opts.Verifier is nil.
+func ForceDebug(opts *ForceDebugOptions) func(http.Handler) http.Handler {
+	if opts == nil || opts.Verifier == nil {
+		panic("sloghttp: ForceDebug requires a Verifier")
+	}
+	o := *opts
+	if o.Header == "" {
+		o.Header = DefaultForceDebugHeader
+	}
+	if o.Key == "" {
+		o.Key = DefaultKeyForceDebug
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelDebug
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(o.Header)
+			if value == "" || !o.Verifier(r, value) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := slogctx.WithLevelOverride(r.Context(), o.Level)
+			if propagate.Initialized(ctx) {
+				ctx = propagate.With(ctx, o.Key, true)
+			} else {
+				ctx = slogctx.Prepend(ctx, o.Key, true)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ForceDebugVerifierHMAC returns a ForceDebugVerifier that accepts tokens
+// made by ForceDebugToken with the same secret, until they expire.
+// Tokens that expire more than maxAge in the future are rejected, so that
+// a leaked token can not be used forever.
+func ForceDebugVerifierHMAC(secret []byte, maxAge time.Duration) ForceDebugVerifier {
+	return func(_ *http.Request, value string) bool {
+		expiry, sig, ok := strings.Cut(value, ".")
+		if !ok {
+			return false
+		}
+		unix, err := strconv.ParseInt(expiry, 10, 64)
+		if err != nil {
+			return false
+		}
+		now := time.Now()
+		if exp := time.Unix(unix, 0); !exp.After(now) || exp.After(now.Add(maxAge)) {
+			return false
+		}
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			return false
+		}
+		return hmac.Equal(got, forceDebugMAC(secret, expiry))
+	}
+}
+
+// ForceDebugToken returns a token for the ForceDebug header that is accepted
+// by ForceDebugVerifierHMAC with the same secret, until it expires.
+// The token looks like "1700000000.3f2a...", the expiry and its hmac-sha256.
+func ForceDebugToken(secret []byte, expires time.Time) string {
+	expiry := strconv.FormatInt(expires.Unix(), 10)
+	return expiry + "." + hex.EncodeToString(forceDebugMAC(secret, expiry))
+}
+
+func forceDebugMAC(secret []byte, expiry string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(expiry))
+	return mac.Sum(nil)
+}
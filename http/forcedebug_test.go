@@ -0,0 +1,96 @@
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func TestForceDebug(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+	secret := []byte("super-secret")
+
+	// Debug lines are suppressed, unless forced
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithSuppressBelow(slog.LevelInfo),
+	)(ForceDebug(&ForceDebugOptions{
+		Verifier: ForceDebugVerifierHMAC(secret, time.Hour),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slogctx.Debug(r.Context(), "details")
+	})))
+
+	now := time.Now()
+	tests := []struct {
+		path  string
+		token string
+	}{
+		{path: "/valid", token: ForceDebugToken(secret, now.Add(time.Minute))},
+		{path: "/expired", token: ForceDebugToken(secret, now.Add(-time.Minute))},
+		{path: "/too-long", token: ForceDebugToken(secret, now.Add(2*time.Hour))},
+		{path: "/wrong-secret", token: ForceDebugToken([]byte("other-secret"), now.Add(time.Minute))},
+		{path: "/garbage", token: "123.not-hex"},
+		{path: "/none"},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.token != "" {
+			req.Header.Set(DefaultForceDebugHeader, tc.token)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"DEBUG","msg":"details","debug_forced":true}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","debug_forced":true,"http_path":"/valid"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/expired"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/too-long"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/wrong-secret"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/garbage"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_path":"/none"}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestForceDebugRequiresVerifier(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic without a Verifier")
+		}
+	}()
+	ForceDebug(&ForceDebugOptions{})
+}
+
+func TestForceDebugHeaderRedacted(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_path": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithRequestHeaders("*"),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(DefaultForceDebugHeader, ForceDebugToken([]byte("super-secret"), time.Now().Add(time.Minute)))
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","req":{"headers":{"X-Debug-Log":"[REDACTED]"}}}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
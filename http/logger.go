@@ -0,0 +1,214 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"github.com/veqryn/slog-context/propagate"
+)
+
+// Logger returns an http middleware that logs one line per request, after
+// the request has been handled. The line includes the method, path, route
+// pattern, status code, request and response byte counts, duration, peer
+// address and user agent. If the connection was hijacked (such as for a
+// WebSocket), the line has "hijacked" instead of the unknown status and bytes.
+//
+// The middleware also initializes the attribute collection (same as
+// sloghttp.AttrCollection), so any attributes added by sloghttp.With further
+// down the stack are included in the log line, as long as the slogctx.Handler
+// is configured with the sloghttp.ExtractAttrCollection extractor.
+//
+// The route pattern is available if the handler was registered with
+// sloghttp.Handle, or with go 1.23+ if the request was routed by an
+// http.ServeMux that is inside this middleware.
+//
+// Later middlewares and handlers can add counters and timings to the log line
+// with sloghttp.Count and sloghttp.Time. See WithCanonical and WithSuppressBelow for using the log
+// line as a single wide event per request.
+//
+// The client IP (see sloghttp.ClientIP and WithTrustedProxies) is added to the
+// context, and can be retrieved by later middlewares with ClientIPFromCtx.
+func Logger(opts ...Option) func(http.Handler) http.Handler {
+	// Closure over config
+	cfg := newConfig(opts, roleServer)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// It is a no-op if propagation was already initialized on the context.
+			// The request state lets later middlewares pass the route and counters back to us.
+			ctx := withRequestState(propagate.Init(r.Context()), cfg.Canonical)
+			ctx = context.WithValue(ctx, clientIPKey{}, ClientIP(r, cfg.TrustedProxies, cfg.ForwardedHeader))
+
+			// Our own log lines use the context from before any suppression
+			logCtx := ctx
+			if cfg.SuppressBelow != nil {
+				ctx = slogctx.WithMinLevel(ctx, cfg.SuppressBelow)
+			}
+			r = r.WithContext(ctx)
+
+			// r is now a shallow copy, so we can replace the body
+			var body *bodyCounter
+			if r.Body != nil && r.Body != http.NoBody {
+				body = &bodyCounter{ReadCloser: r.Body}
+				if cfg.Capture.ReqBodyLimit > 0 && cfg.Capture.captureBody(r.Header.Get("Content-Type")) {
+					body.capture = newBodyBuffer(cfg.Capture.ReqBodyLimit)
+				}
+				r.Body = body
+			}
+			ww := newResponseWriter(w)
+			if cfg.ServerTiming {
+				state := stateFromCtx(ctx)
+				ww.beforeHeader = func(h http.Header) {
+					if timing := state.serverTiming(); timing != "" {
+						h.Add("Server-Timing", timing)
+					}
+				}
+			}
+			if cfg.Capture.RespBodyLimit > 0 {
+				// The content type may not be set until the handler writes, so it is checked when the header is sent
+				ww.capture = newBodyBuffer(cfg.Capture.RespBodyLimit)
+				ww.captureType = cfg.Capture.captureBody
+			}
+
+			// See if we should skip logging this request. The filter is called
+			// once, when the response starts streaming or after it is handled.
+			var filterOnce sync.Once
+			var skip bool
+			skipLog := func() bool {
+				filterOnce.Do(func() {
+					skip = cfg.RequestFilter != nil && !cfg.RequestFilter(r)
+				})
+				return skip
+			}
+
+			// Call the next middleware or the actual handler
+			before := time.Now()
+			stopWatchdog := cfg.startWatchdog(logCtx, r.Method, r.URL.Path, before)
+			defer stopWatchdog() // In case the handler panics
+			stopStream := func() {}
+			if cfg.Streaming {
+				ww.onStream = func() {
+					if !skipLog() {
+						stopStream = cfg.startStream(logCtx, r, ww, before)
+					}
+				}
+				defer func() { stopStream() }()
+			}
+			next.ServeHTTP(ww, r)
+			if !ww.hijacked {
+				// If nothing was written, net/http sends the header after we return
+				ww.implicitHeader()
+			}
+			elapsed := time.Since(before)
+			stopWatchdog()
+			stopStream()
+
+			if skipLog() {
+				return
+			}
+
+			// Log the response
+			cfg.logResponse(logCtx, r, ww, body, elapsed)
+		})
+	}
+}
+
+// level returns the log level for the request, based on the status code and route.
+func (c *config) level(r *http.Request, status int) slog.Level {
+	if status >= 400 {
+		return c.StatusToLevel(status)
+	}
+	if c.RouteLevels != nil {
+		route, _ := requestRoute(r)
+		if lvl, ok := c.RouteLevels[route]; ok {
+			return lvl
+		}
+		if lvl, ok := c.RouteLevels[r.URL.Path]; ok {
+			return lvl
+		}
+	}
+	return c.DefaultLevel
+}
+
+func (c *config) appendCommon(attrs []slog.Attr, r *http.Request) []slog.Attr {
+	attrs = c.AppendToAttributes(attrs, slog.String("http_method", r.Method))
+	attrs = c.AppendToAttributes(attrs, slog.String("http_path", r.URL.Path))
+	// A route recorded by sloghttp.Handle is already in the collected attributes
+	if route, recorded := requestRoute(r); route != "" && !recorded {
+		attrs = c.AppendToAttributes(attrs, slog.String("http_route", route))
+	}
+	return attrs
+}
+
+func (c *config) appendPeer(attrs []slog.Attr, r *http.Request) []slog.Attr {
+	if len(c.TrustedProxies) > 0 {
+		attrs = c.AppendToAttributes(attrs, slog.String("client_ip", ClientIPFromCtx(r.Context())))
+	}
+	host, port := splitHostPort(r.RemoteAddr)
+	attrs = c.AppendToAttributes(attrs, slog.String("peer_host", host))
+	attrs = c.AppendToAttributes(attrs, slog.Int("peer_port", port))
+	attrs = c.AppendToAttributes(attrs, slog.String("user_agent", r.UserAgent()))
+	return attrs
+}
+
+func (c *config) appendDurationElapsed(attrs []slog.Attr, durationElapsed time.Duration) []slog.Attr {
+	// Use floating point division here for higher precision (instead of Millisecond method).
+	return c.AppendToAttributes(attrs, slog.Float64("ms", float64(durationElapsed)/float64(time.Millisecond)))
+}
+
+func (c *config) logResponse(ctx context.Context, r *http.Request, w *responseWriter, body *bodyCounter, elapsed time.Duration) {
+	var reqBytes int64
+	var reqBody *bodyBuffer
+	if body != nil {
+		reqBytes = body.bytes
+		reqBody = body.capture
+	}
+
+	status := w.Status()
+	attrs := c.appendCommon(make([]slog.Attr, 0, 14), r)
+	attrs = c.appendStatus(attrs, w)
+	attrs = c.AppendToAttributes(attrs, slog.Int64("req_bytes", reqBytes))
+	if !w.hijacked {
+		attrs = c.AppendToAttributes(attrs, slog.Int64("resp_bytes", w.bytes.Load()))
+	}
+	if c.Streaming && w.streaming {
+		attrs = c.AppendToAttributes(attrs, slog.Int64("flushes", w.flushes.Load()))
+	}
+	attrs = c.appendDurationElapsed(attrs, elapsed)
+	attrs = c.appendPeer(attrs, r)
+	if counters := stateFromCtx(r.Context()).counterAttrs(); len(counters) > 0 {
+		attrs = c.AppendToAttributes(attrs, slog.Attr{Key: "counters", Value: slog.GroupValue(counters...)})
+	}
+	attrs = c.appendRequestCapture(attrs, r, reqBody)
+	attrs = c.appendResponseCapture(attrs, w.Header(), w.capture)
+
+	level := c.level(r, status)
+	msg := "httpResp"
+	if c.Canonical {
+		msg = "httpCanonical"
+		if panicAttrs := stateFromCtx(r.Context()).panicAttrs(); len(panicAttrs) > 0 {
+			attrs = append(attrs, panicAttrs...)
+			level = max(level, slog.LevelError)
+		}
+	} else if c.Streaming && w.streaming {
+		msg = "httpStreamEnd"
+	}
+	c.LogFunc(ctx, level, msg, attrs...)
+}
+
+// splitHostPort returns the host and port of the address, or empty values if
+// the address can not be parsed.
+func splitHostPort(addr string) (string, int) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0
+	}
+	port, _ := strconv.Atoi(p)
+	return host, port
+}
@@ -0,0 +1,157 @@
+// The module targets go 1.21, so opt in to the go 1.22+ http.ServeMux routing
+//go:debug httpmuxgo121=0
+
+package sloghttp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+	"github.com/veqryn/slog-context/internal/test"
+)
+
+var testAppendToAttributes = disableFields{"ms": {}}
+
+func newTestLoggerCtx() (*test.Handler, context.Context) {
+	tester := &test.Handler{}
+	h := slogctx.NewHandler(
+		tester,
+		&slogctx.HandlerOptions{
+			Prependers: []slogctx.AttrExtractor{
+				ExtractAttrCollection,
+				slogctx.ExtractPrepended,
+			},
+		},
+	)
+	return tester, slogctx.NewCtx(context.Background(), slog.New(h))
+}
+
+func TestLogger(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		With(r.Context(), "user", "bob")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("Hello " + string(body)))
+	})
+
+	handler := Logger(WithAppendToAttributes(testAppendToAttributes.appendToAttrs))(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/users/24680", strings.NewReader("Bob"))
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", "test-agent")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatal("Expected status code of 201; Got: ", rec.Code)
+	}
+	if rec.Body.String() != "Hello Bob" {
+		t.Fatal("Response body incorrect: ", rec.Body.String())
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","user":"bob","http_method":"POST","http_path":"/users/24680","http_route":"/users/","http_status":201,"req_bytes":3,"resp_bytes":9,"peer_host":"192.0.2.1","peer_port":1234,"user_agent":"test-agent"}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestLoggerOptions(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	var logged []slog.Attr
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "user_agent": {}}.appendToAttrs),
+		WithDefaultLevel(slog.LevelDebug),
+		WithLogFunc(func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+			logged = attrs
+			slogctx.LogAttrs(ctx, level, msg, attrs...)
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req = req.WithContext(ctx)
+	req.RemoteAddr = "[::1]:8080"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(logged) != 7 {
+		t.Error("Expected 7 attributes; Got:", logged)
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"DEBUG","msg":"httpResp","http_method":"GET","http_path":"/health","http_status":200,"req_bytes":0,"resp_bytes":0,"peer_host":"::1","peer_port":8080}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestLoggerLevelsAndFilters(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /quiet/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/status/{code}", func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+		w.WriteHeader(code)
+	})
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithRequestFilter(func(r *http.Request) bool {
+			return RequestFilterIgnorePaths("/metrics")(r) && RequestFilterIgnoreRoutes("GET /healthz")(r)
+		}),
+		WithRouteLevels(map[string]slog.Level{"GET /quiet/{id}": slog.LevelDebug, "/status/302": slog.LevelDebug}),
+		WithStatusToLevel(func(status int) slog.Level {
+			if status == http.StatusNotFound {
+				return slog.LevelInfo
+			}
+			return StatusToLevelDefault(status)
+		}),
+	)(mux)
+
+	for _, path := range []string{"/healthz", "/metrics", "/quiet/1", "/status/200", "/status/302", "/status/404", "/status/409", "/status/503"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"DEBUG","msg":"httpResp","http_method":"GET","http_path":"/quiet/1","http_route":"GET /quiet/{id}","http_status":200}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_method":"GET","http_path":"/status/200","http_route":"/status/{code}","http_status":200}
+{"time":"2023-09-29T13:00:59Z","level":"DEBUG","msg":"httpResp","http_method":"GET","http_path":"/status/302","http_route":"/status/{code}","http_status":302}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_method":"GET","http_path":"/status/404","http_route":"/status/{code}","http_status":404}
+{"time":"2023-09-29T13:00:59Z","level":"WARN","msg":"httpResp","http_method":"GET","http_path":"/status/409","http_route":"/status/{code}","http_status":409}
+{"time":"2023-09-29T13:00:59Z","level":"ERROR","msg":"httpResp","http_method":"GET","http_path":"/status/503","http_route":"/status/{code}","http_status":503}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !go1.22
+
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// pathValueAttrs returns nothing, because http.Request.PathValue was only
+// added in go 1.22.
+func pathValueAttrs(_ *http.Request, _ []string) []slog.Attr {
+	return nil
+}
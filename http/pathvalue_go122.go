@@ -0,0 +1,17 @@
+//go:build go1.22
+
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// pathValueAttrs returns the values of the named wildcards matched by the http.ServeMux.
+func pathValueAttrs(r *http.Request, wildcards []string) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(wildcards))
+	for _, name := range wildcards {
+		attrs = append(attrs, slog.String(name, r.PathValue(name)))
+	}
+	return attrs
+}
@@ -0,0 +1,11 @@
+//go:build !go1.23
+
+package sloghttp
+
+import "net/http"
+
+// routePattern returns an empty string, because http.Request.Pattern was
+// only added in go 1.23.
+func routePattern(_ *http.Request) string {
+	return ""
+}
@@ -0,0 +1,10 @@
+//go:build go1.23
+
+package sloghttp
+
+import "net/http"
+
+// routePattern returns the http.ServeMux pattern that matched the request.
+func routePattern(r *http.Request) string {
+	return r.Pattern
+}
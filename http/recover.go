@@ -0,0 +1,117 @@
+package sloghttp
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/veqryn/slog-context/propagate"
+)
+
+// RecoverOptions are options for the Recover middleware
+type RecoverOptions struct {
+	// Handler writes the response after a panic, if the response headers have
+	// not already been sent. If left nil, a plain 500 Internal Server Error is written.
+	Handler http.Handler
+
+	// MaxStackFrames is the maximum number of stack frames logged.
+	// If zero, 32 is used. If negative, the stack trace is not logged.
+	MaxStackFrames int
+
+	// LogFunc is the logging function. If left nil, LogFuncDefault is used.
+	LogFunc LogFunc
+}
+
+// Recover returns an http middleware that recovers from panics in later
+// middlewares and the final http request handler. The panic is logged at
+// error level with the panic value, a trimmed stack trace, and all attributes
+// in the context, including those added by sloghttp.With.
+// Then, if the response headers have not already been sent, a 500 response is written.
+//
+// As net/http expects, an http.ErrAbortHandler panic is not logged, and is
+// re-panicked so that the server aborts the response.
+//
+// Recover also initializes the attribute collection (same as sloghttp.AttrCollection).
+// Place it inside sloghttp.Logger, so that the request log line records the 500 status.
+// If that Logger uses WithCanonical, the panic and stack are added to its
+// request log line instead of being logged separately.
+// If opts is nil, the default options are used.
+func Recover(opts *RecoverOptions) func(http.Handler) http.Handler {
+	o := RecoverOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Handler == nil {
+		o.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		})
+	}
+	if o.MaxStackFrames == 0 {
+		o.MaxStackFrames = 32
+	}
+	if o.LogFunc == nil {
+		o.LogFunc = LogFuncDefault
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// It is a no-op if propagation was already initialized on the context.
+			r = r.WithContext(propagate.Init(r.Context()))
+			ww := newResponseWriter(w)
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(rec)
+				}
+
+				attrs := make([]slog.Attr, 0, 4)
+				attrs = append(attrs, slog.Any("panic", rec))
+				if o.MaxStackFrames > 0 {
+					attrs = append(attrs, slog.Any("stack", panicStack(o.MaxStackFrames)))
+				}
+				// A canonical Logger adds the panic to its request log line, instead of a separate line
+				if !stateFromCtx(r.Context()).foldPanic(attrs) {
+					attrs = append(attrs, slog.String("http_method", r.Method))
+					attrs = append(attrs, slog.String("http_path", r.URL.Path))
+					o.LogFunc(r.Context(), slog.LevelError, "httpPanic", attrs...)
+				}
+
+				if !ww.wroteHeader && !ww.hijacked {
+					o.Handler.ServeHTTP(ww, r)
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// panicStack returns the stack of the panicking goroutine, formatted as one
+// "function file:line" string per frame. It must be called from the deferred
+// function that recovered. The runtime's own panic frames are trimmed.
+func panicStack(maxFrames int) []string {
+	// skip [runtime.Callers, panicStack, the deferred function]
+	pcs := make([]uintptr, maxFrames+8)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]string, 0, maxFrames)
+	for {
+		f, more := frames.Next()
+		// Skip runtime.gopanic, runtime.panicmem, runtime.sigpanic, etc
+		if !(len(stack) == 0 && strings.HasPrefix(f.Function, "runtime.")) {
+			stack = append(stack, f.Function+" "+f.File+":"+strconv.Itoa(f.Line))
+		}
+		if !more || len(stack) >= maxFrames {
+			break
+		}
+	}
+	return stack
+}
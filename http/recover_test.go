@@ -0,0 +1,130 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	handler := Logger(WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs))(
+		Recover(nil)(
+			http.HandlerFunc(panickingHandler),
+		),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(ctx))
+
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "Internal Server Error\n" {
+		t.Error("Expected a 500 response; Got:", rec.Code, rec.Body.String())
+	}
+
+	if len(tester.Records) != 2 {
+		t.Fatal("Expected 2 log lines; Got:", len(tester.Records))
+	}
+	var stack []string
+	var panicAttrs []string
+	tester.Records[0].Attrs(func(a slog.Attr) bool {
+		if a.Key == "stack" {
+			stack = a.Value.Any().([]string)
+		} else {
+			panicAttrs = append(panicAttrs, a.String())
+		}
+		return true
+	})
+	if tester.Records[0].Level != slog.LevelError || tester.Records[0].Message != "httpPanic" {
+		t.Error("Unexpected panic log line:", tester.Records[0])
+	}
+	if got := strings.Join(panicAttrs, " "); got != "user=bob panic=boom http_method=GET http_path=/panic" {
+		t.Error("Unexpected panic attributes:", got)
+	}
+	if len(stack) == 0 || !strings.HasPrefix(stack[0], "github.com/veqryn/slog-context/http.panickingHandler ") ||
+		!strings.Contains(stack[0], "recover_test.go:") {
+		t.Error("Expected the stack to start at the panicking function; Got:", stack)
+	}
+
+	// The request log line comes after, and has the 500 status
+	tester.Records = tester.Records[1:]
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"ERROR","msg":"httpResp","user":"bob","http_method":"GET","http_path":"/panic","http_status":500,"resp_bytes":22}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestRecoverOptions(t *testing.T) {
+	var logged []string
+	logFunc := func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+		line := level.String() + " " + msg
+		for _, a := range attrs {
+			line += " " + a.String()
+		}
+		logged = append(logged, line)
+	}
+
+	handler := Recover(&RecoverOptions{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+		MaxStackFrames: -1,
+		LogFunc:        logFunc,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/partial" {
+			_, _ = w.Write([]byte("partial"))
+		}
+		panic(http.StatusText(http.StatusTeapot))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Error("Expected the custom response; Got:", rec.Code)
+	}
+
+	// Headers already sent, so the response can not be changed
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/partial", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Error("Expected the partial response; Got:", rec.Code, rec.Body.String())
+	}
+
+	expected := `ERROR httpPanic panic=I'm a teapot http_method=GET http_path=/
+ERROR httpPanic panic=I'm a teapot http_method=GET http_path=/partial`
+	if got := strings.Join(logged, "\n"); got != expected {
+		t.Error("Expected:\n", expected, "\nGot:\n", got)
+	}
+
+	// ErrAbortHandler must be re-panicked, and not logged
+	logged = nil
+	handler = Recover(&RecoverOptions{LogFunc: logFunc})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	func() {
+		defer func() {
+			if rec := recover(); rec != http.ErrAbortHandler {
+				t.Error("Expected ErrAbortHandler to be re-panicked; Got:", rec)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	if len(logged) != 0 {
+		t.Error("Expected no logs; Got:", logged)
+	}
+}
+
+func panickingHandler(_ http.ResponseWriter, r *http.Request) {
+	With(r.Context(), "user", "bob")
+	panic("boom")
+}
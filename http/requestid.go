@@ -0,0 +1,193 @@
+package sloghttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"github.com/veqryn/slog-context/propagate"
+)
+
+// DefaultRequestIDHeader is the default http header that request ID's are read from and echoed to.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// DefaultKeyRequestID is the default attribute key sent to slog for request ID's.
+const DefaultKeyRequestID = "request_id"
+
+// RequestIDGenerator creates a new request ID
+type RequestIDGenerator func() string
+
+// RequestIDValidator returns true if an incoming request ID is safe to use
+type RequestIDValidator func(id string) bool
+
+// RequestIDOptions are options for the RequestID middleware
+type RequestIDOptions struct {
+	// Header is the http header that the request ID is read from, and echoed
+	// back to in the response. If left empty, DefaultRequestIDHeader is used.
+	Header string
+
+	// Key is the attribute key used for the request ID in the log lines.
+	// If left empty, DefaultKeyRequestID is used.
+	Key string
+
+	// Generator creates new request ID's, when the request did not come with a
+	// valid one. If left nil, RequestIDGeneratorUUIDv4 is used.
+	Generator RequestIDGenerator
+
+	// Validator checks incoming request ID's, which will be replaced by a newly
+	// generated ID if they fail. If left nil, RequestIDValidatorDefault is used.
+	Validator RequestIDValidator
+
+	// IgnoreIncoming causes incoming request ID's to be ignored, and a new ID
+	// always generated. Use this if the clients are not trusted.
+	IgnoreIncoming bool
+}
+
+// RequestID returns an http middleware that reads the request ID from the
+// incoming request header, or generates a new one if missing or invalid.
+// The request ID is echoed back in the response header, and is added to the
+// context so that it is included on all log lines for the request.
+//
+// If the attribute collection has already been initialized by an earlier
+// middleware (such as sloghttp.AttrCollection or sloghttp.Logger), the
+// request ID is added with sloghttp.With, so that it is visible to those
+// earlier middlewares too. Otherwise, it is added with slogctx.Prepend.
+//
+// The request ID can also be retrieved with RequestIDFromCtx.
+// If opts is nil, the default options are used.
+func RequestID(opts *RequestIDOptions) func(http.Handler) http.Handler {
+	o := RequestIDOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Header == "" {
+		o.Header = DefaultRequestIDHeader
+	}
+	if o.Key == "" {
+		o.Key = DefaultKeyRequestID
+	}
+	if o.Generator == nil {
+		o.Generator = RequestIDGeneratorUUIDv4
+	}
+	if o.Validator == nil {
+		o.Validator = RequestIDValidatorDefault
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var id string
+			if !o.IgnoreIncoming {
+				id = r.Header.Get(o.Header)
+			}
+			if id == "" || !o.Validator(id) {
+				id = o.Generator()
+			}
+
+			w.Header().Set(o.Header, id)
+
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			if propagate.Initialized(ctx) {
+				ctx = propagate.With(ctx, o.Key, id)
+			} else {
+				ctx = slogctx.Prepend(ctx, o.Key, id)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestIDKey is how we find the request ID in the context
+type requestIDKey struct{}
+
+// RequestIDFromCtx returns the request ID stored in the context by the
+// RequestID middleware, or an empty string.
+func RequestIDFromCtx(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDValidatorDefault allows request ID's of up to 128 characters,
+// made of ascii letters, digits, and the characters "-", "_", ".", and ":".
+// This prevents malicious clients from injecting arbitrary content into logs.
+func RequestIDValidatorDefault(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == ':' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// RequestIDGeneratorUUIDv4 generates a random (version 4) UUID,
+// such as "0f8fad5b-d9cb-469f-a165-70867728950e".
+func RequestIDGeneratorUUIDv4() string {
+	var b [16]byte
+	randomBytes(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // Variant is 10
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
+
+// crockford is the base32 alphabet used by ULID's
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// RequestIDGeneratorULID generates a ULID, which is lexicographically sortable
+// by creation time, such as "01ARZ3NDEKTSV4RRFFQ69G5FAV".
+func RequestIDGeneratorULID() string {
+	// 48 bits of millisecond timestamp, followed by 80 bits of randomness
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	randomBytes(b[6:])
+
+	// Encode the 128 bits as 26 characters of 5 bits each, most significant
+	// first. The first character only holds the top 3 bits.
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var buf [26]byte
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+// RequestIDGeneratorHex generates 16 random bytes, hex encoded,
+// such as "4bf92f3577b34da6a3ce929d0e0e4736".
+func RequestIDGeneratorHex() string {
+	var b [16]byte
+	randomBytes(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// randomBytes fills the slice with cryptographically secure random bytes.
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		// Only happens if the operating system's randomness source is broken
+		panic(err)
+	}
+}
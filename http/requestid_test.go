@@ -0,0 +1,115 @@
+package sloghttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func TestRequestID(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	var ctxID string
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxID = RequestIDFromCtx(r.Context())
+		slogctx.Info(r.Context(), "handling")
+	})
+
+	// Without an earlier attribute collection, it is prepended to the context
+	handler := RequestID(nil)(final)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(ctx))
+
+	if ctxID != "abc-123" || rec.Header().Get("X-Request-ID") != "abc-123" {
+		t.Error("Expected the incoming request ID to be used; Got:", ctxID, rec.Header().Get("X-Request-ID"))
+	}
+
+	// With sloghttp.Logger, it is added to the collection, so the request log line has it
+	handler = Logger(WithAppendToAttributes(disableFields{"ms": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs))(
+		RequestID(&RequestIDOptions{
+			Header:    "X-Correlation-ID",
+			Key:       "correlation_id",
+			Generator: func() string { return "generated" },
+		})(final),
+	)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", "bad\n{\"injected\":true}")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(ctx))
+
+	if ctxID != "generated" || rec.Header().Get("X-Correlation-ID") != "generated" {
+		t.Error("Expected the invalid request ID to be replaced; Got:", ctxID, rec.Header().Get("X-Correlation-ID"))
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"handling","request_id":"abc-123"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"handling","correlation_id":"generated"}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","correlation_id":"generated","http_method":"GET","http_path":"/","http_status":200,"req_bytes":0,"resp_bytes":0}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+
+	if RequestIDFromCtx(context.Background()) != "" {
+		t.Error("Expected an empty request ID")
+	}
+}
+
+func TestRequestIDValidatorDefault(t *testing.T) {
+	tests := map[string]bool{
+		"":                             false,
+		"abc-123_DEF.456:789":          true,
+		strings.Repeat("a", 128):       true,
+		strings.Repeat("a", 129):       false,
+		"has space":                    false,
+		"new\nline":                    false,
+		`quote"`:                       false,
+		"unicode-é":                    false,
+		"0f8fad5b-d9cb-469f-a165-7086": true,
+	}
+	for id, valid := range tests {
+		if RequestIDValidatorDefault(id) != valid {
+			t.Errorf("Expected %q to be valid=%t", id, valid)
+		}
+	}
+}
+
+func TestRequestIDGenerators(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator RequestIDGenerator
+		format    *regexp.Regexp
+	}{
+		{"uuidv4", RequestIDGeneratorUUIDv4, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"ulid", RequestIDGeneratorULID, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)},
+		{"hex", RequestIDGeneratorHex, regexp.MustCompile(`^[0-9a-f]{32}$`)},
+	}
+	for _, tc := range tests {
+		id1, id2 := tc.generator(), tc.generator()
+		if !tc.format.MatchString(id1) || !RequestIDValidatorDefault(id1) {
+			t.Errorf("%s: unexpected format: %s", tc.name, id1)
+		}
+		if id1 == id2 {
+			t.Errorf("%s: expected unique ID's; Got: %s", tc.name, id1)
+		}
+	}
+
+	// ULID's sort by time
+	first := RequestIDGeneratorULID()
+	if first[:10] > RequestIDGeneratorULID()[:10] {
+		t.Error("Expected ULID timestamps to be sortable")
+	}
+}
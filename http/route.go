@@ -0,0 +1,78 @@
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Handle registers the handler for the pattern on the http.ServeMux, same as
+// mux.Handle, but wraps the handler so that once the request has been routed,
+// the pattern is added as "http_route" and the named wildcards of the pattern
+// are added in an "http_path_values" group, using sloghttp.With.
+//
+// This lets log lines report the low-cardinality route (ex: "GET /users/{id}")
+// even when other middlewares between sloghttp.Logger and the ServeMux have
+// replaced the request, and it works on go 1.22, which has no http.Request.Pattern.
+// sloghttp.Logger then uses the recorded route instead of adding its own
+// "http_route", and for WithRouteLevels and RequestFilterIgnoreRoutes.
+//
+// Path values require go 1.22+ and its http.ServeMux routing.
+func Handle(mux *http.ServeMux, pattern string, handler http.Handler) {
+	mux.Handle(pattern, Route(pattern, handler))
+}
+
+// HandleFunc registers the handler function for the pattern on the
+// http.ServeMux, same as mux.HandleFunc, but with the route attributes of Handle.
+func HandleFunc(mux *http.ServeMux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	Handle(mux, pattern, http.HandlerFunc(handler))
+}
+
+// Route returns the handler wrapped to record the route pattern and path
+// values, as described in Handle. The pattern must be the same one that the
+// handler is registered for on the http.ServeMux.
+func Route(pattern string, next http.Handler) http.Handler {
+	wildcards := patternWildcards(pattern)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stateFromCtx(r.Context()).setRoute(pattern)
+
+		args := make([]any, 0, 2)
+		args = append(args, slog.String("http_route", pattern))
+		if values := pathValueAttrs(r, wildcards); len(values) > 0 {
+			args = append(args, slog.Attr{Key: "http_path_values", Value: slog.GroupValue(values...)})
+		}
+		next.ServeHTTP(w, r.WithContext(With(r.Context(), args...)))
+	})
+}
+
+// requestRoute returns the route recorded by Route, or else the pattern that
+// the request was routed by. The bool is true if the route was recorded by
+// Route, meaning it has already been added to the collected attributes.
+func requestRoute(r *http.Request) (string, bool) {
+	if route := stateFromCtx(r.Context()).getRoute(); route != "" {
+		return route, true
+	}
+	return routePattern(r), false
+}
+
+// patternWildcards returns the names of the wildcards in an http.ServeMux
+// pattern, such as "id" and "rest" for "GET /users/{id}/{rest...}".
+func patternWildcards(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
@@ -0,0 +1,69 @@
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func TestRoute(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	mux := http.NewServeMux()
+	HandleFunc(mux, "GET /users/{id}/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		slogctx.Info(r.Context(), "in handler")
+		_, _ = w.Write([]byte("file"))
+	})
+	HandleFunc(mux, "GET /healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+	// A middleware between the Logger and the mux replaces the request
+	replacing := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(r.Context()))
+		})
+	}
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithRouteLevels(map[string]slog.Level{"GET /users/{id}/files/{path...}": slog.LevelDebug}),
+		WithRequestFilter(RequestFilterIgnoreRoutes("GET /healthz")),
+	)(replacing(mux))
+
+	for _, path := range []string{"/users/24680/files/a/b.txt", "/healthz"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"in handler","http_route":"GET /users/{id}/files/{path...}","http_path_values":{"id":"24680","path":"a/b.txt"}}
+{"time":"2023-09-29T13:00:59Z","level":"DEBUG","msg":"httpResp","http_route":"GET /users/{id}/files/{path...}","http_path_values":{"id":"24680","path":"a/b.txt"},"http_method":"GET","http_path":"/users/24680/files/a/b.txt","http_status":200}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestPatternWildcards(t *testing.T) {
+	tests := map[string][]string{
+		"/":                                nil,
+		"GET /users/{id}":                  {"id"},
+		"example.com/{a}/{b}/{$}":          {"a", "b"},
+		"POST /files/{dir}/{rest...}":      {"dir", "rest"},
+		"/static/{$}":                      nil,
+		"/broken/{unterminated":            nil,
+		"GET example.com/{org}/x/{repo}/y": {"org", "repo"},
+	}
+	for pattern, expected := range tests {
+		if got := patternWildcards(pattern); !reflect.DeepEqual(got, expected) {
+			t.Error("Pattern:", pattern, "Expected:", expected, "Got:", got)
+		}
+	}
+}
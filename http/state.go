@@ -0,0 +1,103 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// stateKey is the context key for the *requestState
+type stateKey struct{}
+
+// requestState is created by sloghttp.Logger for each request, and lets later
+// middlewares and handlers pass values back out to the Logger, which only has
+// the request from before they replaced it.
+// All methods are safe to call on a nil *requestState, and are no-ops.
+type requestState struct {
+	canonical bool // Set once by the Logger, before the state is shared
+
+	mu       sync.Mutex
+	route    string
+	counters []counter   // In the order first counted
+	timings  []timing    // In the order first timed
+	panic    []slog.Attr // Folded into the canonical log line by sloghttp.Recover
+}
+
+type counter struct {
+	name string
+	n    int64
+}
+
+type timing struct {
+	name string
+	d    time.Duration
+}
+
+// withRequestState returns a context with a new requestState
+func withRequestState(ctx context.Context, canonical bool) context.Context {
+	return context.WithValue(ctx, stateKey{}, &requestState{canonical: canonical})
+}
+
+// withoutRequestState returns a context that hides any requestState of its parent
+func withoutRequestState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stateKey{}, (*requestState)(nil))
+}
+
+// stateFromCtx returns the requestState, or nil if there is none
+func stateFromCtx(ctx context.Context) *requestState {
+	s, _ := ctx.Value(stateKey{}).(*requestState)
+	return s
+}
+
+func (s *requestState) setRoute(route string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.route = route
+}
+
+func (s *requestState) getRoute() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.route
+}
+
+// Count adds n to the named counter for the current request, such as the
+// number of database queries or cache misses. The counters are logged by
+// sloghttp.Logger in a "counters" group on the request log line.
+// It is a no-op if the context did not come from a request inside sloghttp.Logger.
+func Count(ctx context.Context, name string, n int64) {
+	s := stateFromCtx(ctx)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.counters {
+		if s.counters[i].name == name {
+			s.counters[i].n += n
+			return
+		}
+	}
+	s.counters = append(s.counters, counter{name: name, n: n})
+}
+
+// counterAttrs returns the counters as attributes
+func (s *requestState) counterAttrs() []slog.Attr {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attrs := make([]slog.Attr, 0, len(s.counters))
+	for _, c := range s.counters {
+		attrs = append(attrs, slog.Int64(c.name, c.n))
+	}
+	return attrs
+}
@@ -0,0 +1,73 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WithStreaming returns an Option to log long-lived responses, such as
+// Server-Sent Events, chunked streaming, and hijacked WebSocket connections.
+// A response is treated as streaming once it is flushed or hijacked.
+// Then an "httpStreamStart" line is logged, followed by an "httpStreamProgress"
+// line with the bytes written and flush count every progressInterval (if it
+// is greater than zero), and the request log line is logged as
+// "httpStreamEnd", with the total flush count and duration.
+// Only used by Logger.
+func WithStreaming(progressInterval time.Duration) Option {
+	return streamingOption{progressInterval: progressInterval}
+}
+
+type streamingOption struct {
+	progressInterval time.Duration
+}
+
+func (o streamingOption) apply(c *config) {
+	c.Streaming = true
+	c.StreamProgressInterval = o.progressInterval
+}
+
+// startStream logs the "httpStreamStart" line, and starts logging progress.
+// It returns a function to stop logging progress. It logs nothing for a
+// canonical Logger, which only logs one line per request.
+func (c *config) startStream(ctx context.Context, r *http.Request, w *responseWriter, before time.Time) func() {
+	if c.Canonical {
+		return func() {}
+	}
+
+	attrs := c.appendCommon(make([]slog.Attr, 0, 6), r)
+	attrs = c.appendStatus(attrs, w)
+	attrs = c.appendDurationElapsed(attrs, time.Since(before))
+	c.LogFunc(ctx, c.DefaultLevel, "httpStreamStart", attrs...)
+
+	if c.StreamProgressInterval <= 0 {
+		return func() {}
+	}
+
+	// Only read values that are safe to read from another goroutine
+	method, path := r.Method, r.URL.Path
+	return startRepeating(c.StreamProgressInterval, c.StreamProgressInterval, func() {
+		attrs := make([]slog.Attr, 0, 5)
+		attrs = c.AppendToAttributes(attrs, slog.String("http_method", method))
+		attrs = c.AppendToAttributes(attrs, slog.String("http_path", path))
+		attrs = c.AppendToAttributes(attrs, slog.Int64("resp_bytes", w.bytes.Load()))
+		attrs = c.AppendToAttributes(attrs, slog.Int64("flushes", w.flushes.Load()))
+		attrs = c.appendDurationElapsed(attrs, time.Since(before))
+		c.LogFunc(ctx, c.DefaultLevel, "httpStreamProgress", attrs...)
+	})
+}
+
+// appendStatus adds the status code, or if the connection was hijacked,
+// "hijacked" and the status only if one was written before the hijack.
+// After a hijack, the handler writes its response directly to the connection,
+// so the status and bytes written are unknown.
+func (c *config) appendStatus(attrs []slog.Attr, w *responseWriter) []slog.Attr {
+	if w.hijacked {
+		if w.wroteHeader {
+			attrs = c.AppendToAttributes(attrs, slog.Int("http_status", w.Status()))
+		}
+		return c.AppendToAttributes(attrs, slog.Bool("hijacked", true))
+	}
+	return c.AppendToAttributes(attrs, slog.Int("http_status", w.Status()))
+}
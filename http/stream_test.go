@@ -0,0 +1,137 @@
+package sloghttp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreaming(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	progressed := make(chan struct{}, 100)
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithStreaming(5*time.Millisecond),
+		WithLogFunc(func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+			LogFuncDefault(ctx, level, msg, attrs...)
+			if msg == "httpStreamProgress" {
+				progressed <- struct{}{}
+			}
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-progressed:
+		case <-time.After(5 * time.Second):
+			t.Error("Expected a progress line")
+		}
+
+		_, _ = w.Write([]byte("data: 2\n\n"))
+		w.(http.Flusher).Flush()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx))
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The number of progress lines depends on timing, so only keep the first
+	var lines []string
+	var progress int
+	for _, line := range strings.SplitAfter(string(jsn), "\n") {
+		if strings.Contains(line, `"msg":"httpStreamProgress"`) {
+			if progress++; progress > 1 {
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpStreamStart","http_method":"GET","http_path":"/events","http_status":200}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpStreamProgress","http_method":"GET","http_path":"/events","resp_bytes":9,"flushes":1}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpStreamEnd","http_method":"GET","http_path":"/events","http_status":200,"resp_bytes":18,"flushes":2}
+`
+	if got := strings.Join(lines, ""); got != expected {
+		t.Error("Expected:", expected, "\nGot:", got)
+	}
+}
+
+func TestStreamingFiltered(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	var calls int
+	handler := Logger(
+		WithStreaming(time.Millisecond),
+		WithRequestFilter(func(r *http.Request) bool {
+			calls++
+			return RequestFilterIgnorePaths("/events")(r)
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(5 * time.Millisecond)
+		w.(http.Flusher).Flush()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx))
+
+	// The filter is called once, when the stream starts, and skips all of its lines
+	if calls != 1 || len(tester.Records) != 0 {
+		t.Error("Expected 1 filter call and no log lines; Got:", calls, len(tester.Records))
+	}
+}
+
+func TestHijacked(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "req_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = buf.Flush()
+	}))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Error("Expected 101; Got:", resp.StatusCode)
+	}
+	srv.Close() // Wait for the handler to finish
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpResp","http_method":"GET","http_path":"/ws","hijacked":true}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
@@ -0,0 +1,132 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithServerTiming returns an Option to send the timings recorded with
+// sloghttp.Time in the Server-Timing response header, so that they can be
+// seen in browser developer tools. Only the timings that have stopped before
+// the response header is sent are included.
+// Do not use it if the timings should not be visible to clients.
+// Only used by Logger.
+func WithServerTiming() Option {
+	return serverTimingOption{}
+}
+
+type serverTimingOption struct{}
+
+func (o serverTimingOption) apply(c *config) {
+	c.ServerTiming = true
+}
+
+// Time starts timing the named part of the current request, such as "db",
+// and returns a function that stops it. Durations with the same name are
+// added together. The timings are added to the attribute collection (same as
+// sloghttp.With) as a "timings" group (in milliseconds), so they are on the
+// request log line of sloghttp.Logger, and on any later log line of the
+// request, with the timings stopped so far. They are also sent in the
+// Server-Timing response header if WithServerTiming is used.
+// Characters in the name that are not allowed in an HTTP header token are
+// replaced with "_".
+// It is a no-op if the context did not come from a request inside sloghttp.Logger.
+//
+//	defer sloghttp.Time(ctx, "db")()
+func Time(ctx context.Context, name string) func() {
+	s := stateFromCtx(ctx)
+	if s == nil {
+		return func() {}
+	}
+	name = timingName(name)
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if first := s.addTiming(name, time.Since(start)); first {
+				With(ctx, slog.Any("timings", timingsValue{s: s}))
+			}
+		})
+	}
+}
+
+// addTiming adds the duration to the named timing. It returns true if it is
+// the first timing of the request.
+func (s *requestState) addTiming(name string, d time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.timings {
+		if s.timings[i].name == name {
+			s.timings[i].d += d
+			return false
+		}
+	}
+	s.timings = append(s.timings, timing{name: name, d: d})
+	return len(s.timings) == 1
+}
+
+// timingsValue is a slog.LogValuer of the timings recorded so far, because
+// the attribute collection can only be appended to.
+type timingsValue struct {
+	s *requestState
+}
+
+func (v timingsValue) LogValue() slog.Value {
+	return slog.GroupValue(v.s.timingAttrs()...)
+}
+
+// timingName replaces the characters that are not allowed in an HTTP header
+// token (RFC 9110), so that the name can be used in the Server-Timing header.
+func timingName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if isTokenChar(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func isTokenChar(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// timingAttrs returns the timings as attributes, in milliseconds
+func (s *requestState) timingAttrs() []slog.Attr {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attrs := make([]slog.Attr, 0, len(s.timings))
+	for _, t := range s.timings {
+		attrs = append(attrs, slog.Float64(t.name, float64(t.d)/float64(time.Millisecond)))
+	}
+	return attrs
+}
+
+// serverTiming returns the timings formatted for the Server-Timing header,
+// such as "db;dur=12.3, cache;dur=0.4"
+func (s *requestState) serverTiming() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parts := make([]string, 0, len(s.timings))
+	for _, t := range s.timings {
+		ms := strconv.FormatFloat(float64(t.d)/float64(time.Millisecond), 'f', 1, 64)
+		parts = append(parts, t.name+";dur="+ms)
+	}
+	return strings.Join(parts, ", ")
+}
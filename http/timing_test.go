@@ -0,0 +1,78 @@
+package sloghttp
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func TestTime(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"ms": {}, "http_method": {}, "http_path": {}, "http_status": {}, "req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithServerTiming(),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 2; i++ {
+			stop := Time(r.Context(), "db")
+			time.Sleep(2 * time.Millisecond)
+			stop()
+			stop() // Only counted once
+		}
+		Time(r.Context(), "cache")()
+		Time(r.Context(), "tmpl; dur=1, ok")()
+
+		// Later log lines have the timings too
+		slogctx.Info(r.Context(), "rendering")
+
+		// Not in the header, because it is still running when the header is sent
+		stopRender := Time(r.Context(), "render")
+		_, _ = w.Write([]byte("Hello"))
+		stopRender()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	header := rec.Header().Get("Server-Timing")
+	if !regexp.MustCompile(`^db;dur=\d+\.\d, cache;dur=\d+\.\d, tmpl__dur_1__ok;dur=\d+\.\d$`).MatchString(header) {
+		t.Error("Unexpected Server-Timing header:", header)
+	}
+
+	if len(tester.Records) != 2 {
+		t.Fatal("Expected 2 log lines; Got:", len(tester.Records))
+	}
+	// The test handler keeps the timings unresolved, so both lines have all of them
+	for _, record := range tester.Records {
+		timings := map[string]float64{}
+		record.Attrs(func(a slog.Attr) bool {
+			if a.Key == "timings" {
+				for _, ga := range a.Value.Resolve().Group() {
+					timings[ga.Key] = ga.Value.Float64()
+				}
+			}
+			return true
+		})
+		if len(timings) != 4 || timings["db"] < 4 || timings["db"] > 1000 {
+			t.Error("Unexpected timings:", timings)
+		}
+	}
+
+	// The header is still sent if the handler writes nothing
+	handler = Logger(WithServerTiming())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Time(r.Context(), "db")()
+	}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if header = rec.Result().Header.Get("Server-Timing"); !regexp.MustCompile(`^db;dur=\d+\.\d$`).MatchString(header) {
+		t.Error("Unexpected Server-Timing header:", header)
+	}
+
+	// Time is a no-op outside of the Logger
+	Time(ctx, "ignored")()
+}
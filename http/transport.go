@@ -0,0 +1,148 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"github.com/veqryn/slog-context/propagate"
+)
+
+// Transport returns an http.RoundTripper that logs all outbound requests and
+// their responses, using the request's context. This means the log lines will
+// have all the attributes of the caller's context (with the default
+// slogctx.LogAttrs logging function).
+// The duration is measured until the response headers have been received.
+//
+// With the WithRequestIDHeader and WithAttrHeaders options, the request ID and
+// allowlisted context attributes are also sent to the server as headers.
+// If next is nil, http.DefaultTransport is used.
+func Transport(next http.RoundTripper, opts ...Option) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{
+		next: next,
+		cfg:  newConfig(opts, roleClient),
+	}
+}
+
+// transport wraps around the next http.RoundTripper
+type transport struct {
+	next http.RoundTripper
+	cfg  *config
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = t.cfg.injectHeaders(req)
+
+	// See if we should skip logging this request
+	// The context may be from an inbound request, so hide its route from the filter
+	if t.cfg.RequestFilter != nil && !t.cfg.RequestFilter(req.WithContext(withoutRequestState(req.Context()))) {
+		return t.next.RoundTrip(req)
+	}
+
+	var ct *clientTrace
+	if t.cfg.ClientTrace != ClientTraceOff {
+		req, ct = t.cfg.newClientTrace(req)
+	}
+
+	// Call the next round tripper
+	before := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	// Log the response
+	t.cfg.logClientResponse(req.Context(), req, resp, err, time.Since(before), ct)
+	return resp, err
+}
+
+// injectHeaders returns a clone of the request with the request ID and
+// allowlisted attributes added as headers, or the original request if there
+// is nothing to add. A RoundTripper must not modify the original request.
+func (c *config) injectHeaders(req *http.Request) *http.Request {
+	if c.RequestIDHeader == "" && len(c.AttrHeaders) == 0 {
+		return req
+	}
+
+	ctx := req.Context()
+	headers := make(http.Header)
+	if c.RequestIDHeader != "" {
+		if id := RequestIDFromCtx(ctx); id != "" {
+			headers.Set(c.RequestIDHeader, id)
+		}
+	}
+
+	if len(c.AttrHeaders) > 0 {
+		// Later sources are more specific to this context, so they take priority
+		now := time.Now()
+		for _, extract := range []slogctx.AttrExtractor{propagate.ExtractAttrs, slogctx.ExtractPrepended, slogctx.ExtractAppended} {
+			for _, a := range extract(ctx, now, slog.LevelInfo, "") {
+				header, ok := c.AttrHeaders[a.Key]
+				if !ok {
+					continue
+				}
+				if v := a.Value.Resolve().String(); validHeaderValue(v) {
+					headers.Set(header, v)
+				}
+			}
+		}
+	}
+
+	if len(headers) == 0 {
+		return req
+	}
+	req = req.Clone(ctx)
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	return req
+}
+
+// validHeaderValue returns true if the value can be sent as a header without
+// being rejected by net/http, or being used to inject additional headers.
+func validHeaderValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+	return v != ""
+}
+
+func (c *config) logClientResponse(ctx context.Context, req *http.Request, resp *http.Response, err error, elapsed time.Duration, ct *clientTrace) {
+	attrs := make([]slog.Attr, 0, 8)
+	attrs = c.AppendToAttributes(attrs, slog.String("http_method", req.Method))
+	attrs = c.AppendToAttributes(attrs, slog.String("http_host", req.URL.Host))
+	attrs = c.AppendToAttributes(attrs, slog.String("http_path", req.URL.Path))
+
+	var level slog.Level
+	if err != nil {
+		level = c.ErrorToLevel(err)
+		attrs = c.AppendToAttributes(attrs, slogctx.Err(err))
+	} else {
+		level = c.clientLevel(req, resp.StatusCode)
+		attrs = c.AppendToAttributes(attrs, slog.Int("http_status", resp.StatusCode))
+	}
+	attrs = c.appendDurationElapsed(attrs, elapsed)
+	if ct != nil {
+		attrs = c.AppendToAttributes(attrs, ct.attr())
+	}
+
+	c.LogFunc(ctx, level, "httpClientResp", attrs...)
+}
+
+// clientLevel returns the log level for an outbound request, based on the
+// status code and url path. Outbound requests have no route, and the context
+// may have the route of an inbound request, so it is not used.
+func (c *config) clientLevel(req *http.Request, status int) slog.Level {
+	if status >= 400 {
+		return c.StatusToLevel(status)
+	}
+	if lvl, ok := c.RouteLevels[req.URL.Path]; ok {
+		return lvl
+	}
+	return c.DefaultLevel
+}
@@ -0,0 +1,116 @@
+package sloghttp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func TestTransport(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	var gotHeaders http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	client := &http.Client{Transport: Transport(nil,
+		WithAppendToAttributes(testAppendToAttributes.appendToAttrs),
+		WithRequestIDHeader(DefaultRequestIDHeader),
+		WithAttrHeaders(map[string]string{"tenant": "X-Tenant", "user": "X-User", "bad": "X-Bad"}),
+	)}
+
+	ctx = context.WithValue(ctx, requestIDKey{}, "abc-123")
+	ctx = With(ctx, "tenant", "acme")
+	ctx = slogctx.Prepend(ctx, "user", 42, "bad", "line\r\nX-Injected: true", "other", "not-sent")
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/missing", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if len(req.Header) != 0 {
+		t.Error("Expected the original request to not be modified:", req.Header)
+	}
+	if gotHeaders.Get("X-Request-ID") != "abc-123" || gotHeaders.Get("X-Tenant") != "acme" || gotHeaders.Get("X-User") != "42" {
+		t.Error("Expected headers to be injected; Got:", gotHeaders)
+	}
+	if gotHeaders.Get("X-Bad") != "" || gotHeaders.Get("X-Injected") != "" {
+		t.Error("Expected invalid header values to be skipped; Got:", gotHeaders)
+	}
+
+	req, _ = http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/upstream", nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	// Error, with the context canceled
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	req, _ = http.NewRequestWithContext(canceledCtx, http.MethodGet, srv.URL+"/canceled", nil)
+	_, err = client.Do(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected context canceled; Got:", err)
+	}
+
+	jsn, err := tester.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpClientResp","tenant":"acme","user":42,"bad":"line\r\nX-Injected: true","other":"not-sent","http_method":"GET","http_host":"` + host + `","http_path":"/missing","http_status":404}
+{"time":"2023-09-29T13:00:59Z","level":"WARN","msg":"httpClientResp","tenant":"acme","user":42,"bad":"line\r\nX-Injected: true","other":"not-sent","http_method":"POST","http_host":"` + host + `","http_path":"/upstream","http_status":502}
+{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"httpClientResp","tenant":"acme","user":42,"bad":"line\r\nX-Injected: true","other":"not-sent","http_method":"GET","http_host":"` + host + `","http_path":"/canceled","err":"context canceled"}
+`
+	if string(jsn) != expected {
+		t.Error("Expected:", expected, "\nGot:", string(jsn))
+	}
+}
+
+func TestTransportInboundRoute(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+
+	// The route of the inbound request does not apply to the outbound request
+	client := &http.Client{Transport: Transport(nil,
+		WithRequestFilter(RequestFilterIgnoreRoutes("GET /healthz")),
+		WithRouteLevels(map[string]slog.Level{"GET /healthz": slog.LevelDebug, "/db-ping": slog.LevelWarn}),
+	)}
+
+	mux := http.NewServeMux()
+	HandleFunc(mux, "GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, upstream.URL+"/db-ping", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = resp.Body.Close()
+	})
+
+	Logger(WithRequestFilter(RequestFilterIgnoreRoutes("GET /healthz")))(mux).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil).WithContext(ctx))
+
+	if len(tester.Records) != 1 || tester.Records[0].Message != "httpClientResp" || tester.Records[0].Level != slog.LevelWarn {
+		t.Error("Expected only the outbound request to be logged, at the level of its path; Got:", tester.Records)
+	}
+}
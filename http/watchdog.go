@@ -0,0 +1,87 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WithWatchdog returns an Option to log a warning for each request that is
+// still being handled after the threshold, so that hung or stuck handlers can
+// be seen before they finish (or if they never finish). If interval is
+// greater than zero, the warning is repeated at that interval until the
+// request finishes. The warning includes all collected context attributes,
+// the method, path, and the milliseconds elapsed so far.
+// Only used by Logger.
+func WithWatchdog(threshold, interval time.Duration) Option {
+	return watchdogOption{threshold: threshold, interval: interval}
+}
+
+type watchdogOption struct {
+	threshold time.Duration
+	interval  time.Duration
+}
+
+func (o watchdogOption) apply(c *config) {
+	c.WatchdogThreshold = o.threshold
+	c.WatchdogInterval = o.interval
+}
+
+// startWatchdog starts a timer that logs an "httpInFlight" warning once the
+// request has been running past the threshold. It returns a function to stop
+// the timer, which is safe to call more than once.
+func (c *config) startWatchdog(ctx context.Context, method, path string, before time.Time) func() {
+	if c.WatchdogThreshold <= 0 {
+		return func() {}
+	}
+	return startRepeating(c.WatchdogThreshold, c.WatchdogInterval, func() {
+		attrs := make([]slog.Attr, 0, 3)
+		attrs = c.AppendToAttributes(attrs, slog.String("http_method", method))
+		attrs = c.AppendToAttributes(attrs, slog.String("http_path", path))
+		attrs = c.appendDurationElapsed(attrs, time.Since(before))
+		c.LogFunc(ctx, slog.LevelWarn, "httpInFlight", attrs...)
+	})
+}
+
+// startRepeating calls f after the delay, then repeats at the interval if it
+// is greater than zero. It returns a function to stop the timer, which is
+// safe to call more than once, and guarantees f is not called after it returns.
+// f is called without holding the lock, so that a slow log handler does not
+// block stopping the timer, other than to wait for a call already running.
+func startRepeating(delay, interval time.Duration, f func()) func() {
+	var mu sync.Mutex
+	var stopped bool
+	var running sync.WaitGroup
+	var timer *time.Timer
+
+	// Hold the lock until the timer is assigned, in case it fires immediately
+	mu.Lock()
+	defer mu.Unlock()
+	timer = time.AfterFunc(delay, func() {
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			return
+		}
+		running.Add(1)
+		mu.Unlock()
+
+		f()
+
+		mu.Lock()
+		defer mu.Unlock()
+		running.Done()
+		if !stopped && interval > 0 {
+			timer.Reset(interval)
+		}
+	})
+
+	return func() {
+		mu.Lock()
+		stopped = true
+		timer.Stop()
+		mu.Unlock()
+		running.Wait()
+	}
+}
@@ -0,0 +1,109 @@
+package sloghttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	tester, ctx := newTestLoggerCtx()
+
+	release := make(chan struct{})
+	warned := make(chan struct{}, 100)
+	handler := Logger(
+		WithAppendToAttributes(disableFields{"req_bytes": {}, "resp_bytes": {}, "peer_host": {}, "peer_port": {}, "user_agent": {}}.appendToAttrs),
+		WithWatchdog(5*time.Millisecond, 5*time.Millisecond),
+		WithLogFunc(func(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+			LogFuncDefault(ctx, level, msg, attrs...)
+			if msg == "httpInFlight" {
+				warned <- struct{}{}
+			}
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		With(r.Context(), "user", "bob")
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stuck", nil).WithContext(ctx))
+	}()
+
+	// Wait for the warning to repeat at least once
+	for i := 0; i < 2; i++ {
+		select {
+		case <-warned:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected repeated in-flight warnings")
+		}
+	}
+	close(release)
+	<-done
+
+	inFlight := len(warned)
+	time.Sleep(20 * time.Millisecond)
+	if got := len(warned); got != inFlight {
+		t.Error("Expected no warnings after the request finished; Got:", got-inFlight)
+	}
+
+	last := tester.Records[len(tester.Records)-1]
+	if last.Message != "httpResp" {
+		t.Error("Expected the request log line last; Got:", last.Message)
+	}
+	for _, r := range tester.Records[:len(tester.Records)-1] {
+		var attrs []string
+		var ms float64
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "ms" {
+				ms = a.Value.Float64()
+			} else {
+				attrs = append(attrs, a.String())
+			}
+			return true
+		})
+		if r.Level != slog.LevelWarn || r.Message != "httpInFlight" || ms < 5 ||
+			len(attrs) != 3 || attrs[0] != "user=bob" || attrs[1] != "http_method=GET" || attrs[2] != "http_path=/stuck" {
+			t.Error("Unexpected in-flight log line:", r.Level, r.Message, attrs, ms)
+		}
+	}
+}
+
+func TestStartRepeatingStop(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int64
+	stop := startRepeating(time.Millisecond, time.Millisecond, func() {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+	})
+	<-started
+
+	// Stop waits for the call that is already running
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Expected stop to wait for the running call")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+
+	n := calls.Load()
+	time.Sleep(5 * time.Millisecond)
+	stop() // Safe to call again
+	if calls.Load() != n || n != 1 {
+		t.Error("Expected 1 call, and none after stop; Got:", n, calls.Load())
+	}
+}
@@ -0,0 +1,199 @@
+package sloghttp
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// responseWriter wraps around the embedded http.ResponseWriter, and records
+// the status code, number of bytes written, flushes, and if it was hijacked.
+// It keeps the http.Flusher, http.Hijacker, and io.ReaderFrom behavior of the
+// wrapped writer, and supports http.ResponseController through Unwrap.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	bytes       atomic.Int64 // Atomic, because streaming progress is logged from another goroutine
+	flushes     atomic.Int64
+	wroteHeader bool
+	hijacked    bool
+	streaming   bool
+
+	// capture is a copy of the start of the body, if body capture is enabled
+	capture *bodyBuffer
+
+	// captureType reports if the body of the content type should be captured.
+	// It is checked when the header is sent, to stop capturing other bodies.
+	captureType func(contentType string) bool
+
+	// beforeHeader is called right before the final header is sent, if not nil
+	beforeHeader func(http.Header)
+
+	// onStream is called on the first flush or hijack, if not nil
+	onStream func()
+}
+
+var (
+	_ http.Flusher  = &responseWriter{} // Assert conformance with interfaces
+	_ http.Hijacker = &responseWriter{}
+	_ io.ReaderFrom = &responseWriter{}
+)
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+// Status returns the status code sent, defaulting to 200 OK if nothing was sent
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	// Informational 1xx headers can be sent multiple times before the final
+	// header, so only the final header is recorded (101 Switching Protocols is final).
+	if !w.wroteHeader && (code < 100 || code > 199 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+		w.wroteHeader = true
+		w.sendingHeader()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.implicitHeader()
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes.Add(int64(n))
+	if w.capture != nil {
+		_, _ = w.capture.Write(b[:n])
+	}
+	return n, err
+}
+
+// ReadFrom lets the wrapped writer use its own io.ReaderFrom (for example
+// sendfile on a *net.TCPConn), while still counting the bytes written.
+// If the body is being captured, only the start of the body is copied through
+// Write, so that the rest can still use the fast path.
+func (w *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.implicitHeader()
+	if w.capture == nil {
+		return w.readFrom(src)
+	}
+
+	var n int64
+	if room := w.capture.room(); room > 0 {
+		// Hide our ReadFrom from io.CopyN, so it uses Write
+		var err error
+		n, err = io.CopyN(struct{ io.Writer }{w}, src, int64(room))
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+	}
+	rest, err := w.readFrom(src)
+	if rest > 0 {
+		w.capture.truncated = true
+	}
+	return n + rest, err
+}
+
+// readFrom copies src to the wrapped writer, and counts the bytes written
+func (w *responseWriter) readFrom(src io.Reader) (int64, error) {
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, src)
+	}
+	w.bytes.Add(n)
+	return n, err
+}
+
+// Flush implements http.Flusher. It is a no-op if the wrapped writer can not flush.
+func (w *responseWriter) Flush() {
+	_ = w.FlushError()
+}
+
+// FlushError is used by http.ResponseController to flush and return any error.
+func (w *responseWriter) FlushError() error {
+	w.implicitHeader()
+	err := http.NewResponseController(w.ResponseWriter).Flush()
+	if err == nil {
+		w.flushes.Add(1)
+		w.startStream()
+	}
+	return err
+}
+
+// Hijack implements http.Hijacker. It returns an error wrapping
+// http.ErrNotSupported if the wrapped writer can not be hijacked.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+		w.startStream()
+	}
+	return conn, rw, err
+}
+
+// startStream records that the response is being streamed, the first time
+// that it is flushed or hijacked.
+func (w *responseWriter) startStream() {
+	if !w.streaming {
+		w.streaming = true
+		if w.onStream != nil {
+			w.onStream()
+		}
+	}
+}
+
+// Unwrap is used by http.ResponseController to reach the wrapped writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// implicitHeader records the 200 OK that net/http sends if the handler starts
+// writing the body without calling WriteHeader first.
+func (w *responseWriter) implicitHeader() {
+	if !w.wroteHeader {
+		w.status = http.StatusOK
+		w.wroteHeader = true
+		w.sendingHeader()
+	}
+}
+
+// sendingHeader is called right before the final header is sent
+func (w *responseWriter) sendingHeader() {
+	if w.beforeHeader != nil {
+		w.beforeHeader(w.Header())
+	}
+	if w.capture != nil && w.captureType != nil && !w.captureType(w.Header().Get("Content-Type")) {
+		w.capture = nil
+	}
+}
+
+// bodyCounter wraps around the request body, and counts the bytes read.
+type bodyCounter struct {
+	io.ReadCloser
+	bytes int64
+
+	// capture is a copy of the start of the body, if body capture is enabled
+	capture *bodyBuffer
+}
+
+func (b *bodyCounter) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if b.capture != nil {
+		_, _ = b.capture.Write(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,102 @@
+package sloghttp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newResponseWriter(rec)
+
+	n, err := w.ReadFrom(strings.NewReader("Hello"))
+	if err != nil || n != 5 {
+		t.Fatal("Unexpected ReadFrom result:", n, err)
+	}
+	_, _ = w.Write([]byte(" World"))
+	w.WriteHeader(http.StatusTeapot) // Superfluous, should be ignored
+
+	if w.Status() != http.StatusOK {
+		t.Error("Expected status 200; Got:", w.Status())
+	}
+	if w.bytes.Load() != 11 {
+		t.Error("Expected 11 bytes; Got:", w.bytes.Load())
+	}
+	if rec.Body.String() != "Hello World" {
+		t.Error("Unexpected body:", rec.Body.String())
+	}
+
+	w.Flush()
+	if !rec.Flushed {
+		t.Error("Expected the recorder to be flushed")
+	}
+
+	// httptest.ResponseRecorder can not be hijacked or have deadlines set
+	if _, _, err = w.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Error("Expected ErrNotSupported; Got:", err)
+	}
+	if w.hijacked {
+		t.Error("Expected hijacked to be false")
+	}
+	if w.Unwrap() != rec {
+		t.Error("Expected Unwrap to return the wrapped writer")
+	}
+}
+
+func TestResponseWriterServer(t *testing.T) {
+	var w *responseWriter
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w = newResponseWriter(rw)
+		rc := http.NewResponseController(w)
+
+		// Reaches the underlying writer through Unwrap
+		if err := rc.SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+			t.Error(err)
+		}
+
+		if r.URL.Path == "/flush" {
+			_, _ = w.Write([]byte("part1"))
+			if err := rc.Flush(); err != nil {
+				t.Error(err)
+			}
+			_, _ = w.Write([]byte("part2"))
+			return
+		}
+
+		conn, buf, err := rc.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = buf.Flush()
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/flush")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "part1part2" || w.bytes.Load() != 10 || w.Status() != http.StatusOK {
+		t.Error("Unexpected flushed response:", string(body), w.bytes.Load(), w.Status())
+	}
+
+	resp, err = http.Get(srv.URL + "/hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(bufio.NewReader(resp.Body))
+	_ = resp.Body.Close()
+	if string(body) != "hijacked" || !w.hijacked {
+		t.Error("Unexpected hijacked response:", string(body), w.hijacked)
+	}
+}
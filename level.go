@@ -0,0 +1,60 @@
+package slogctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Minimum level key for context.valueCtx
+type minLevelKey struct{}
+
+// Level override key for context.valueCtx
+type levelOverrideKey struct{}
+
+// WithMinLevel returns a copy of ctx with a minimum log level. For all log
+// lines using this context, slogctx.Handler drops records below this level, in
+// addition to the level of the next handler, which still applies. It can only
+// make the logs quieter, such as quieting the log lines of a single request
+// (ex: slog.LevelWarn). Use WithLevelOverride to turn on more logs.
+// A nil level removes any minimum level set by a parent context.
+func WithMinLevel(parent context.Context, level slog.Leveler) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, minLevelKey{}, level)
+}
+
+// MinLevelFromCtx returns the minimum log level set by WithMinLevel, or nil if none.
+func MinLevelFromCtx(ctx context.Context) slog.Leveler {
+	if ctx == nil {
+		return nil
+	}
+	if v, ok := ctx.Value(minLevelKey{}).(slog.Leveler); ok {
+		return v
+	}
+	return nil
+}
+
+// WithLevelOverride returns a copy of ctx with a log level that overrides the
+// level of the handlers. For all log lines using this context, slogctx.Handler
+// reports records at or above this level as enabled, even if the next handler
+// (or a minimum level set by WithMinLevel) would drop them. This is meant for explicitly
+// turning on debug logs for a single request or job (ex: slog.LevelDebug).
+// A nil level removes any override set by a parent context.
+func WithLevelOverride(parent context.Context, level slog.Leveler) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, levelOverrideKey{}, level)
+}
+
+// LevelOverrideFromCtx returns the log level set by WithLevelOverride, or nil if none.
+func LevelOverrideFromCtx(ctx context.Context) slog.Leveler {
+	if ctx == nil {
+		return nil
+	}
+	if v, ok := ctx.Value(levelOverrideKey{}).(slog.Leveler); ok {
+		return v
+	}
+	return nil
+}
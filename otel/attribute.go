@@ -0,0 +1,78 @@
+package slogotel
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// appendKeyValues appends the slog attributes as otel attributes, with groups
+// flattened into dotted keys (ex: "group1.key1"), under the prefix.
+func appendKeyValues(kvs []attribute.KeyValue, prefix string, attrs ...slog.Attr) []attribute.KeyValue {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue // Ignore empty attributes, same as the builtin handlers
+		}
+		key := a.Key
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		} else if prefix != "" {
+			key = prefix // Groups with empty keys are inlined
+		}
+
+		if a.Value.Kind() == slog.KindGroup {
+			kvs = appendKeyValues(kvs, key, a.Value.Group()...)
+			continue
+		}
+		kvs = append(kvs, keyValue(key, a.Value))
+	}
+	return kvs
+}
+
+// keyValue converts a resolved, non-group slog value into an otel attribute
+func keyValue(key string, v slog.Value) attribute.KeyValue {
+	switch v.Kind() {
+	case slog.KindString:
+		return attribute.String(key, v.String())
+	case slog.KindInt64:
+		return attribute.Int64(key, v.Int64())
+	case slog.KindUint64:
+		if u := v.Uint64(); u <= math.MaxInt64 {
+			return attribute.Int64(key, int64(u))
+		}
+		return attribute.String(key, v.String())
+	case slog.KindFloat64:
+		return attribute.Float64(key, v.Float64())
+	case slog.KindBool:
+		return attribute.Bool(key, v.Bool())
+	case slog.KindDuration:
+		return attribute.String(key, v.Duration().String())
+	case slog.KindTime:
+		return attribute.String(key, v.Time().Format(time.RFC3339Nano))
+	}
+
+	switch a := v.Any().(type) {
+	case error:
+		return attribute.String(key, a.Error())
+	case fmt.Stringer:
+		return attribute.String(key, a.String())
+	case []string:
+		return attribute.StringSlice(key, a)
+	case []int:
+		return attribute.IntSlice(key, a)
+	case []int64:
+		return attribute.Int64Slice(key, a)
+	case []float64:
+		return attribute.Float64Slice(key, a)
+	case []bool:
+		return attribute.BoolSlice(key, a)
+	case []byte:
+		return attribute.String(key, string(a))
+	default:
+		return attribute.String(key, fmt.Sprintf("%+v", a))
+	}
+}
@@ -0,0 +1,77 @@
+package slogotel
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BaggageOptions are options for NewBaggageExtractor
+type BaggageOptions struct {
+	// Keys are the keys of the baggage members that are added to log lines,
+	// in this order. Other baggage members are ignored.
+	Keys []string
+
+	// Rename maps baggage member keys to log attribute keys, such as
+	// "tenant" to "tenant_id". Keys not in the map keep their name.
+	Rename map[string]string
+}
+
+// NewBaggageExtractor returns an AttrExtractor that adds the allowed W3C
+// Baggage members in the context as string attributes, so that values that
+// flow between services in the baggage (such as tenant or experiment id's)
+// are added to all log lines.
+func NewBaggageExtractor(opts BaggageOptions) slogctx.AttrExtractor {
+	type mapping struct{ member, attr string }
+	keys := make([]mapping, 0, len(opts.Keys))
+	for _, k := range opts.Keys {
+		attr := k
+		if renamed, ok := opts.Rename[k]; ok {
+			attr = renamed
+		}
+		keys = append(keys, mapping{member: k, attr: attr})
+	}
+
+	return func(ctx context.Context, _ time.Time, _ slog.Level, _ string) []slog.Attr {
+		bag := baggage.FromContext(ctx)
+		if bag.Len() == 0 {
+			return nil
+		}
+		var attrs []slog.Attr
+		for _, k := range keys {
+			if m := bag.Member(k.member); m.Key() != "" {
+				attrs = append(attrs, slog.String(k.attr, m.Value()))
+			}
+		}
+		return attrs
+	}
+}
+
+// PrependedToBaggage returns a context with the chosen attributes, that were
+// added to the context with slogctx.Prepend, set as W3C Baggage members, so
+// that they are propagated to downstream services along with the trace.
+// The baggage member keys are the same as the attribute keys, and the values
+// are the string form of the attribute values. If an attribute key is added
+// more than once, the last value is used.
+// It returns an error if a key is not a valid baggage key, or if the baggage
+// would be too large, in which case the original context is returned.
+func PrependedToBaggage(ctx context.Context, keys ...string) (context.Context, error) {
+	bag := baggage.FromContext(ctx)
+	for _, a := range slogctx.ExtractPrepended(ctx, time.Time{}, slog.LevelInfo, "") {
+		if !slices.Contains(keys, a.Key) {
+			continue
+		}
+		m, err := baggage.NewMemberRaw(a.Key, a.Value.Resolve().String())
+		if err != nil {
+			return ctx, err
+		}
+		if bag, err = bag.SetMember(m); err != nil {
+			return ctx, err
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
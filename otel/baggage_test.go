@@ -0,0 +1,71 @@
+package slogotel
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestBaggageExtractor(t *testing.T) {
+	tester := &testHandler{}
+	h := slogctx.NewHandler(
+		tester,
+		&slogctx.HandlerOptions{
+			Prependers: []slogctx.AttrExtractor{
+				NewBaggageExtractor(BaggageOptions{
+					Keys:   []string{"tenant", "experiment", "missing"},
+					Rename: map[string]string{"tenant": "tenant_id"},
+				}),
+				slogctx.ExtractPrepended,
+			},
+		})
+	ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+	bag, err := baggage.Parse("experiment=blue,tenant=acme,secret=hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+
+	slogctx.Info(ctx, "main message")
+
+	expectedText := `time=2023-09-29T13:00:59.000Z level=INFO msg="main message" tenant_id=acme experiment=blue
+`
+	if s := tester.String(); s != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", expectedText, s)
+	}
+}
+
+func TestPrependedToBaggage(t *testing.T) {
+	bag, err := baggage.Parse("existing=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = slogctx.Prepend(ctx, "order_id", 12345, "user_id", "u1", "user_id", "u2", "password", "hunter2")
+
+	ctx, err = PrependedToBaggage(ctx, "order_id", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bag = baggage.FromContext(ctx)
+	expected := map[string]string{"existing": "1", "order_id": "12345", "user_id": "u2"}
+	if bag.Len() != len(expected) {
+		t.Error("Expected:", expected, "Got:", bag.String())
+	}
+	for k, v := range expected {
+		if got := bag.Member(k).Value(); got != v {
+			t.Error("Key:", k, "Expected:", v, "Got:", got)
+		}
+	}
+
+	// Invalid keys return an error and the original context
+	bad := slogctx.Prepend(ctx, "bad\xffkey", "x")
+	if got, err := PrependedToBaggage(bad, "bad\xffkey"); err == nil || got != bad {
+		t.Error("Expected an error and the original context")
+	}
+}
@@ -0,0 +1,68 @@
+package slogotel
+
+import (
+	"encoding/binary"
+	"log/slog"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formatter returns the log attributes that identify the trace and span of a
+// span context, in the shape a particular log backend needs for correlating
+// logs with traces. It is only called with a valid span context.
+type Formatter func(spanCtx trace.SpanContext) []slog.Attr
+
+// Attribute keys used by the vendor formatters.
+const (
+	KeyGCPTrace        = "logging.googleapis.com/trace"
+	KeyGCPSpanID       = "logging.googleapis.com/spanId"
+	KeyGCPTraceSampled = "logging.googleapis.com/trace_sampled"
+	KeyDatadogTraceID  = "dd.trace_id"
+	KeyDatadogSpanID   = "dd.span_id"
+	KeyXRayTraceID     = "AWS-XRAY-TRACE-ID"
+	KeyTraceparent     = "traceparent"
+)
+
+// FormatGCP returns a Formatter for Google Cloud Logging, which correlates
+// log entries with Cloud Trace using the special structured logging fields:
+// logging.googleapis.com/trace="projects/<projectID>/traces/<trace id>",
+// logging.googleapis.com/spanId, and logging.googleapis.com/trace_sampled.
+func FormatGCP(projectID string) Formatter {
+	prefix := "projects/" + projectID + "/traces/"
+	return func(spanCtx trace.SpanContext) []slog.Attr {
+		return []slog.Attr{
+			slog.String(KeyGCPTrace, prefix+spanCtx.TraceID().String()),
+			slog.String(KeyGCPSpanID, spanCtx.SpanID().String()),
+			slog.Bool(KeyGCPTraceSampled, spanCtx.IsSampled()),
+		}
+	}
+}
+
+// FormatDatadog is a Formatter for Datadog, which wants dd.trace_id and
+// dd.span_id as the decimal string of the lower 64 bits of the id's.
+func FormatDatadog(spanCtx trace.SpanContext) []slog.Attr {
+	traceID := spanCtx.TraceID()
+	spanID := spanCtx.SpanID()
+	return []slog.Attr{
+		slog.String(KeyDatadogTraceID, strconv.FormatUint(binary.BigEndian.Uint64(traceID[8:]), 10)),
+		slog.String(KeyDatadogSpanID, strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10)),
+	}
+}
+
+// FormatXRay is a Formatter for AWS X-Ray, which wants AWS-XRAY-TRACE-ID in
+// the format "1-<8 hex digits>-<24 hex digits>@<span id>".
+func FormatXRay(spanCtx trace.SpanContext) []slog.Attr {
+	traceID := spanCtx.TraceID().String()
+	return []slog.Attr{
+		slog.String(KeyXRayTraceID, "1-"+traceID[:8]+"-"+traceID[8:]+"@"+spanCtx.SpanID().String()),
+	}
+}
+
+// FormatTraceparent is a Formatter that adds a single W3C Trace Context
+// traceparent, in the format "00-<trace id>-<span id>-<trace flags>".
+func FormatTraceparent(spanCtx trace.SpanContext) []slog.Attr {
+	return []slog.Attr{
+		slog.String(KeyTraceparent, "00-"+spanCtx.TraceID().String()+"-"+spanCtx.SpanID().String()+"-"+spanCtx.TraceFlags().String()),
+	}
+}
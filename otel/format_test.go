@@ -0,0 +1,80 @@
+package slogotel
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFormatters(t *testing.T) {
+	// Manually create the trace id and span id so the test is repeatable
+	traceID, err := trace.TraceIDFromHex(`0123456789abcdef0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(`0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		formatter Formatter
+		expected  string
+	}{
+		"gcp": {
+			formatter: FormatGCP("my-project"),
+			expected: `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"main message","logging.googleapis.com/trace":"projects/my-project/traces/0123456789abcdef0123456789abcdef","logging.googleapis.com/spanId":"0123456789abcdef","logging.googleapis.com/trace_sampled":true}
+`,
+		},
+		"datadog": {
+			formatter: FormatDatadog,
+			expected: `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"main message","dd.trace_id":"81985529216486895","dd.span_id":"81985529216486895"}
+`,
+		},
+		"xray": {
+			formatter: FormatXRay,
+			expected: `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"main message","AWS-XRAY-TRACE-ID":"1-01234567-89abcdef0123456789abcdef@0123456789abcdef"}
+`,
+		},
+		"traceparent": {
+			formatter: FormatTraceparent,
+			expected: `{"time":"2023-09-29T13:00:59Z","level":"INFO","msg":"main message","traceparent":"00-0123456789abcdef0123456789abcdef-0123456789abcdef-01"}
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tester := &testHandler{}
+			h := slogctx.NewHandler(
+				tester,
+				&slogctx.HandlerOptions{
+					Prependers: []slogctx.AttrExtractor{
+						NewExtractor(Options{Formatter: tc.formatter}),
+					},
+				})
+			ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+			ctx = trace.ContextWithSpan(ctx, &recorderSpan{
+				sc: trace.NewSpanContext(trace.SpanContextConfig{
+					TraceID:    traceID,
+					SpanID:     spanID,
+					TraceFlags: trace.FlagsSampled,
+				}),
+			})
+
+			slogctx.Info(ctx, "main message")
+
+			b, err := tester.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s\n", tc.expected, string(b))
+			}
+		})
+	}
+}
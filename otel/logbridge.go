@@ -0,0 +1,257 @@
+package slogotel
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// DefaultLogBridgeName is the default instrumentation scope name of the
+// otel logger used by the LogBridgeHandler.
+const DefaultLogBridgeName = "github.com/veqryn/slog-context/otel"
+
+// LogBridgeOptions are options for a LogBridgeHandler
+type LogBridgeOptions struct {
+	// LoggerProvider creates the otel logger that the records are emitted to.
+	// If left nil, the global otel LoggerProvider is used.
+	LoggerProvider log.LoggerProvider
+
+	// Name is the instrumentation scope name of the otel logger.
+	// If left empty, DefaultLogBridgeName is used.
+	Name string
+
+	// Version is the instrumentation scope version of the otel logger.
+	Version string
+}
+
+// LogBridgeHandler is a slog.Handler that converts each slog record into an
+// OpenTelemetry log record, and emits it through an otel LoggerProvider, so
+// that logs can be shipped over OTLP with the same pipeline as traces.
+//
+// It is the final handler in the chain. Put it after the slogctx.Handler to
+// include the prepended and appended context attributes:
+//
+//	slogctx.NewHandler(slogotel.NewLogBridgeHandler(&slogotel.LogBridgeOptions{LoggerProvider: provider}), nil)
+//
+// The slog level is mapped to the otel severity, with slog.LevelDebug,
+// slog.LevelInfo, slog.LevelWarn, and slog.LevelError becoming otel Debug, Info,
+// Warn, and Error, and levels in between becoming Debug2, Info3, etc.
+// The otel SDK sets the trace context from the record's context.
+// Groups are kept as nested maps, and slices as otel slices.
+type LogBridgeHandler struct {
+	logger log.Logger
+	groups []bridgeGroup // The first group is the top level, with no name
+}
+
+// bridgeGroup is a group, and the attributes added to it with WithAttrs
+type bridgeGroup struct {
+	name  string
+	attrs []log.KeyValue
+}
+
+var _ slog.Handler = &LogBridgeHandler{} // Assert conformance with interface
+
+// NewLogBridgeHandler creates a LogBridgeHandler slog.Handler that emits all
+// records to an otel logger.
+// If opts is nil, the default options are used.
+func NewLogBridgeHandler(opts *LogBridgeOptions) *LogBridgeHandler {
+	o := LogBridgeOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.LoggerProvider == nil {
+		o.LoggerProvider = global.GetLoggerProvider()
+	}
+	if o.Name == "" {
+		o.Name = DefaultLogBridgeName
+	}
+
+	return &LogBridgeHandler{
+		logger: o.LoggerProvider.Logger(o.Name, log.WithInstrumentationVersion(o.Version)),
+		groups: []bridgeGroup{{}},
+	}
+}
+
+// Enabled reports whether the otel logger will emit records at the given level.
+func (h *LogBridgeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	var r log.Record
+	r.SetSeverity(Severity(level))
+	return h.logger.Enabled(ctx, r)
+}
+
+// Handle converts the record and emits it to the otel logger.
+func (h *LogBridgeHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var record log.Record
+	record.SetTimestamp(r.Time)
+	record.SetBody(log.StringValue(r.Message))
+	record.SetSeverity(Severity(r.Level))
+	record.SetSeverityText(r.Level.String())
+
+	// Attributes of the record go into the innermost group,
+	// which is then nested inside each of the outer groups.
+	last := len(h.groups) - 1
+	kvs := make([]log.KeyValue, 0, len(h.groups[last].attrs)+r.NumAttrs())
+	kvs = append(kvs, h.groups[last].attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		kvs = appendLogKeyValues(kvs, a)
+		return true
+	})
+	for i := last; i > 0; i-- {
+		if len(kvs) == 0 {
+			// Empty groups are omitted, same as the builtin handlers
+			kvs = slices.Clip(h.groups[i-1].attrs)
+			continue
+		}
+		kvs = append(slices.Clip(h.groups[i-1].attrs), log.Map(h.groups[i].name, kvs...))
+	}
+	record.AddAttributes(kvs...)
+
+	h.logger.Emit(ctx, record)
+	return nil
+}
+
+// WithGroup returns a new LogBridgeHandler that still has h's attributes,
+// but any future attributes added will be namespaced.
+func (h *LogBridgeHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), bridgeGroup{name: name})
+	return &h2
+}
+
+// WithAttrs returns a new LogBridgeHandler whose attributes consists of h's attributes followed by attrs.
+func (h *LogBridgeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.groups = slices.Clone(h.groups)
+	last := &h2.groups[len(h2.groups)-1]
+	last.attrs = appendLogKeyValues(slices.Clip(last.attrs), attrs...)
+	return &h2
+}
+
+// Severity returns the otel log severity of a slog level.
+// slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, and slog.LevelError become
+// otel Debug, Info, Warn, and Error, and the levels in between become the
+// numbered severities, such as Info2 for slog.LevelInfo+1.
+func Severity(level slog.Level) log.Severity {
+	// slog levels are 4 apart, same as the otel severities,
+	// with slog.LevelInfo (0) lining up with log.SeverityInfo (9).
+	sev := int(level) + int(log.SeverityInfo)
+	if sev < int(log.SeverityTrace1) {
+		return log.SeverityTrace1
+	}
+	if sev > int(log.SeverityFatal4) {
+		return log.SeverityFatal4
+	}
+	return log.Severity(sev)
+}
+
+// appendLogKeyValues appends the slog attributes as otel log attributes
+func appendLogKeyValues(kvs []log.KeyValue, attrs ...slog.Attr) []log.KeyValue {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue // Ignore empty attributes, same as the builtin handlers
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			group := appendLogKeyValues(nil, a.Value.Group()...)
+			if len(group) == 0 {
+				continue
+			}
+			if a.Key == "" {
+				kvs = append(kvs, group...) // Groups with empty keys are inlined
+				continue
+			}
+			kvs = append(kvs, log.Map(a.Key, group...))
+			continue
+		}
+		kvs = append(kvs, log.KeyValue{Key: a.Key, Value: logValue(a.Value)})
+	}
+	return kvs
+}
+
+// logValue converts a resolved, non-group slog value into an otel log value
+func logValue(v slog.Value) log.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return log.StringValue(v.String())
+	case slog.KindInt64:
+		return log.Int64Value(v.Int64())
+	case slog.KindUint64:
+		if u := v.Uint64(); u <= math.MaxInt64 {
+			return log.Int64Value(int64(u))
+		}
+		return log.StringValue(v.String())
+	case slog.KindFloat64:
+		return log.Float64Value(v.Float64())
+	case slog.KindBool:
+		return log.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return log.Int64Value(v.Duration().Nanoseconds())
+	case slog.KindTime:
+		return log.Int64Value(v.Time().UnixNano())
+	case slog.KindGroup:
+		return log.MapValue(appendLogKeyValues(nil, v.Group()...)...)
+	}
+	return anyLogValue(v.Any())
+}
+
+// anyLogValue converts the value of a slog.KindAny into an otel log value
+func anyLogValue(a any) log.Value {
+	switch a := a.(type) {
+	case nil:
+		return log.Value{}
+	case error:
+		return log.StringValue(a.Error())
+	case []byte:
+		return log.BytesValue(a)
+	case fmt.Stringer:
+		return log.StringValue(a.String())
+	case slog.Value:
+		return logValue(a.Resolve())
+	}
+
+	rv := reflect.ValueOf(a)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		vs := make([]log.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			vs = append(vs, anyLogValue(rv.Index(i).Interface()))
+		}
+		return log.SliceValue(vs...)
+	case reflect.Map:
+		kvs := make([]log.KeyValue, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			kvs = append(kvs, log.KeyValue{Key: fmt.Sprint(iter.Key().Interface()), Value: anyLogValue(iter.Value().Interface())})
+		}
+		// Sort the keys, so the output is repeatable
+		slices.SortFunc(kvs, func(a, b log.KeyValue) int { return cmp.Compare(a.Key, b.Key) })
+		return log.MapValue(kvs...)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return log.Value{}
+		}
+	}
+
+	// Numbers and other basic kinds
+	if v := slog.AnyValue(a); v.Kind() != slog.KindAny {
+		return logValue(v)
+	}
+	return log.StringValue(fmt.Sprintf("%+v", a))
+}
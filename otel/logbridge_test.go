@@ -0,0 +1,151 @@
+package slogotel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// memoryExporter is an in-memory sdklog.Exporter
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func TestLogBridgeHandler(t *testing.T) {
+	exporter := &memoryExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+
+	h := slogctx.NewHandler(NewLogBridgeHandler(&LogBridgeOptions{LoggerProvider: provider}), nil)
+	logger := slog.New(h)
+
+	// Manually create the trace id and span id so the test is repeatable
+	traceID, err := trace.TraceIDFromHex(`0123456789abcdef0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(`0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = slogctx.Prepend(ctx, "prepend1", "arg1")
+	ctx = slogctx.Append(ctx, "append1", 1)
+
+	logger.With("with1", "arg1").WithGroup("group1").WarnContext(ctx, "main message",
+		"dur", time.Second,
+		"tags", []string{"a", "b"},
+		"err", errors.New("some error"),
+		slog.Group("group2", "ok", true, "pi", 3.5),
+		slog.Group("empty"),
+	)
+	logger.DebugContext(ctx, "debug message")
+	logger.Log(ctx, slog.LevelError+2, "error plus two")
+
+	if len(exporter.records) != 3 {
+		t.Fatal("Expected 3 records; Got:", len(exporter.records))
+	}
+
+	r := exporter.records[0]
+	if r.Body().AsString() != "main message" {
+		t.Error("Expected body: main message; Got:", r.Body())
+	}
+	if r.Severity() != log.SeverityWarn || r.SeverityText() != "WARN" {
+		t.Error("Expected severity: WARN; Got:", r.Severity(), r.SeverityText())
+	}
+	if r.TraceID() != traceID || r.SpanID() != spanID || !r.TraceFlags().IsSampled() {
+		t.Error("Expected trace context; Got:", r.TraceID(), r.SpanID(), r.TraceFlags())
+	}
+
+	var got []log.KeyValue
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		got = append(got, kv)
+		return true
+	})
+	expected := []log.KeyValue{
+		log.String("prepend1", "arg1"),
+		log.String("with1", "arg1"),
+		log.Map("group1",
+			log.Int64("dur", int64(time.Second)),
+			log.Slice("tags", log.StringValue("a"), log.StringValue("b")),
+			log.String("err", "some error"),
+			log.Map("group2", log.Bool("ok", true), log.Float64("pi", 3.5)),
+			log.Int64("append1", 1),
+		),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected:\n%v\nGot:\n%v\n", expected, got)
+	}
+
+	if sev := exporter.records[1].Severity(); sev != log.SeverityDebug {
+		t.Error("Expected severity: DEBUG; Got:", sev)
+	}
+	if sev, text := exporter.records[2].Severity(), exporter.records[2].SeverityText(); sev != log.SeverityError3 || text != "ERROR+2" {
+		t.Error("Expected severity: ERROR3; Got:", sev, text)
+	}
+
+	// Groups and attributes of the bridge handler itself
+	slog.New(NewLogBridgeHandler(&LogBridgeOptions{LoggerProvider: provider})).
+		With("with1", "arg1").WithGroup("group1").With("with2", "arg2").WithGroup("group2").
+		Info("main message", "main1", "arg1")
+
+	got = nil
+	exporter.records[3].WalkAttributes(func(kv log.KeyValue) bool {
+		got = append(got, kv)
+		return true
+	})
+	expected = []log.KeyValue{
+		log.String("with1", "arg1"),
+		log.Map("group1",
+			log.String("with2", "arg2"),
+			log.Map("group2", log.String("main1", "arg1")),
+		),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected:\n%v\nGot:\n%v\n", expected, got)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := map[slog.Level]log.Severity{
+		slog.LevelDebug - 8: log.SeverityTrace1,
+		slog.LevelDebug - 4: log.SeverityTrace1,
+		slog.LevelDebug:     log.SeverityDebug,
+		slog.LevelInfo:      log.SeverityInfo,
+		slog.LevelInfo + 1:  log.SeverityInfo2,
+		slog.LevelWarn:      log.SeverityWarn,
+		slog.LevelError:     log.SeverityError,
+		slog.LevelError + 4: log.SeverityFatal,
+		slog.LevelError + 8: log.SeverityFatal4,
+	}
+	for level, expected := range tests {
+		if got := Severity(level); got != expected {
+			t.Error("Level:", level, "Expected:", expected, "Got:", got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package slogotel
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// DefaultMeterName is the default instrumentation scope name of the otel
+// meter used by the MetricsHandler.
+const DefaultMeterName = "github.com/veqryn/slog-context/otel"
+
+// DefaultLogRecordsCounterName is the default name of the counter of log records.
+const DefaultLogRecordsCounterName = "log.records"
+
+// MetricsHandlerOptions are options for a MetricsHandler
+type MetricsHandlerOptions struct {
+	// MeterProvider creates the otel meter for the counter.
+	// If left nil, the global otel MeterProvider is used.
+	MeterProvider metric.MeterProvider
+
+	// CounterName is the name of the counter.
+	// If left empty, DefaultLogRecordsCounterName is used.
+	CounterName string
+
+	// Key is the key of a log attribute, such as "grpc_method" or "http_route",
+	// whose value is added as an attribute of the counter, in addition to the
+	// level. It must only be used with low-cardinality attributes.
+	// Only attributes at the top level of the log record are used.
+	Key string
+}
+
+// MetricsHandler is a slog.Handler middleware that counts the records it
+// handles, per level (and optionally per the value of one attribute), as an
+// otel Int64Counter. This lets alerts on the error log rate come from the
+// metrics pipeline, without waiting on the log backend.
+//
+// Put it after the slogctx.Handler in the chain, so that the attribute can
+// come from the context.
+// It passes the record off to the next handler unchanged.
+type MetricsHandler struct {
+	next    slog.Handler
+	counter metric.Int64Counter
+	key     string
+	value   *slog.Value // Value of the key, if added with WithAttrs
+	grouped bool        // True if there is a group, so later attributes are not at the top level
+}
+
+var _ slog.Handler = &MetricsHandler{} // Assert conformance with interface
+
+// NewMetricsMiddleware creates a slogotel.MetricsHandler slog.Handler middleware
+// that conforms to [github.com/samber/slog-multi.Middleware] interface.
+// It can be used with slogmulti methods such as Pipe to easily setup a pipeline of slog handlers:
+//
+//	slog.SetDefault(slog.New(slogmulti.
+//		Pipe(slogctx.NewMiddleware(&slogctx.HandlerOptions{})).
+//		Pipe(slogotel.NewMetricsMiddleware(&slogotel.MetricsHandlerOptions{})).
+//		Handler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{})),
+//	))
+func NewMetricsMiddleware(options *MetricsHandlerOptions) func(slog.Handler) slog.Handler {
+	return func(next slog.Handler) slog.Handler {
+		return NewMetricsHandler(
+			next,
+			options,
+		)
+	}
+}
+
+// NewMetricsHandler creates a MetricsHandler slog.Handler middleware that
+// counts the log records.
+// If opts is nil, the default options are used.
+// If the counter can not be created, the error is sent to the otel error
+// handler, and nothing is counted.
+func NewMetricsHandler(next slog.Handler, opts *MetricsHandlerOptions) *MetricsHandler {
+	o := MetricsHandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MeterProvider == nil {
+		o.MeterProvider = otel.GetMeterProvider()
+	}
+	if o.CounterName == "" {
+		o.CounterName = DefaultLogRecordsCounterName
+	}
+
+	counter, err := o.MeterProvider.Meter(DefaultMeterName).Int64Counter(
+		o.CounterName,
+		metric.WithDescription("Number of log records, per level."),
+		metric.WithUnit("{record}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	if counter == nil {
+		counter = noop.Int64Counter{}
+	}
+
+	return &MetricsHandler{
+		next:    next,
+		counter: counter,
+		key:     o.Key,
+	}
+}
+
+// Enabled reports whether the next handler handles records at the given level.
+// The handler ignores records whose level is lower.
+func (h *MetricsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle counts the record, then passes it to the next handler.
+func (h *MetricsHandler) Handle(ctx context.Context, r slog.Record) error {
+	kvs := make([]attribute.KeyValue, 1, 2)
+	kvs[0] = attribute.String("level", r.Level.String())
+
+	if h.key != "" {
+		value := h.value
+		if !h.grouped {
+			r.Attrs(func(a slog.Attr) bool {
+				if a.Key == h.key {
+					value = &a.Value
+				}
+				return true
+			})
+		}
+		if value != nil {
+			kvs = append(kvs, attribute.String(h.key, value.Resolve().String()))
+		}
+	}
+
+	h.counter.Add(ctx, 1, metric.WithAttributes(kvs...))
+	return h.next.Handle(ctx, r)
+}
+
+// WithGroup returns a new MetricsHandler that still has h's attributes,
+// but any future attributes added will be namespaced.
+func (h *MetricsHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.grouped = true
+	return &h2
+}
+
+// WithAttrs returns a new MetricsHandler whose attributes consists of h's attributes followed by attrs.
+func (h *MetricsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	if h.key != "" && !h.grouped {
+		for _, a := range attrs {
+			if a.Key == h.key {
+				h2.value = &a.Value
+			}
+		}
+	}
+	return &h2
+}
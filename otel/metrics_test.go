@@ -0,0 +1,69 @@
+package slogotel
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	tester := &testHandler{}
+	h := slogctx.NewHandler(NewMetricsHandler(tester, &MetricsHandlerOptions{MeterProvider: provider, Key: "route"}), nil)
+	logger := slog.New(h)
+
+	ctx := slogctx.Prepend(context.Background(), "route", "/users")
+	logger.ErrorContext(ctx, "main message")
+	logger.ErrorContext(ctx, "main message")
+	logger.InfoContext(ctx, "main message")
+	logger.Warn("no route")
+
+	// Attributes added with WithAttrs, directly on the metrics handler
+	slog.New(NewMetricsHandler(slog.NewTextHandler(io.Discard, nil), &MetricsHandlerOptions{MeterProvider: provider, Key: "route"})).
+		With("route", "/orders").Error("main message")
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatal("Expected 1 metric; Got:", rm.ScopeMetrics)
+	}
+	m := rm.ScopeMetrics[0].Metrics[0]
+	if m.Name != DefaultLogRecordsCounterName {
+		t.Error("Expected:", DefaultLogRecordsCounterName, "Got:", m.Name)
+	}
+
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	if !ok || !sum.IsMonotonic {
+		t.Fatalf("Expected a monotonic int64 sum; Got: %T", m.Data)
+	}
+
+	expected := map[attribute.Set]int64{
+		attribute.NewSet(attribute.String("level", "ERROR"), attribute.String("route", "/users")):  2,
+		attribute.NewSet(attribute.String("level", "INFO"), attribute.String("route", "/users")):   1,
+		attribute.NewSet(attribute.String("level", "WARN")):                                        1,
+		attribute.NewSet(attribute.String("level", "ERROR"), attribute.String("route", "/orders")): 1,
+	}
+	if len(sum.DataPoints) != len(expected) {
+		t.Error("Expected:", len(expected), "data points; Got:", len(sum.DataPoints))
+	}
+	for _, dp := range sum.DataPoints {
+		if dp.Value != expected[dp.Attributes] {
+			t.Error("Attributes:", dp.Attributes.Encoded(attribute.DefaultEncoder()), "Expected:", expected[dp.Attributes], "Got:", dp.Value)
+		}
+	}
+
+	// The records are passed on
+	if tester.Record.Message != "no route" {
+		t.Error("Expected: no route; Got:", tester.Record.Message)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	slogctx "github.com/veqryn/slog-context"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -16,6 +17,10 @@ var DefaultKeyTraceID = "TraceID" // Copied from otel stdouttrace
 // DefaultKeySpanID is the default attribute key sent to slog for span id's.
 var DefaultKeySpanID = "SpanID" // Copied from otel stdouttrace
 
+// DefaultKeyTraceSampled is the default attribute key sent to slog for the sampled flag of the trace,
+// when Options.NonRecording is set.
+var DefaultKeyTraceSampled = "TraceSampled"
+
 // DefaultSpanErrorStatusMinLevel is the minimum slog.Level where the otel span will be set to a status of otel codes.Error
 var DefaultSpanErrorStatusMinLevel = slog.LevelError
 
@@ -26,32 +31,156 @@ var DefaultSpanRecordErrorMinLevel = slog.LevelError
 // if it is not already recording an error through DefaultSpanRecordErrorMinLevel
 var DefaultSpanAddEventMinLevel = slog.LevelError
 
+// Options are options for NewExtractor
+type Options struct {
+	// KeyTraceID is the attribute key for trace id's.
+	// If left empty, DefaultKeyTraceID is used.
+	KeyTraceID string
+
+	// KeySpanID is the attribute key for span id's.
+	// If left empty, DefaultKeySpanID is used.
+	KeySpanID string
+
+	// KeyTraceSampled is the attribute key for the sampled flag of the trace,
+	// which is only added when NonRecording is set.
+	// If left empty, DefaultKeyTraceSampled is used.
+	KeyTraceSampled string
+
+	// NonRecording adds the trace and span id's whenever the span context is
+	// valid, instead of only for recording spans. This keeps the trace id in
+	// the logs when a request was sampled out locally, or when there is only a
+	// remote parent span context, along with a flag saying if it was sampled.
+	// The span itself is still only annotated if it is recording.
+	NonRecording bool
+
+	// Formatter returns the attributes for the trace and span id's, in the
+	// format needed by a log backend, such as FormatGCP or FormatDatadog.
+	// If set, KeyTraceID and KeySpanID are not used.
+	// If left nil, the id's are added as hex strings under KeyTraceID and KeySpanID.
+	Formatter Formatter
+
+	// SpanErrorStatusMinLevel is the minimum level where the span will be set
+	// to a status of otel codes.Error, with the log message as the description.
+	// If left nil, DefaultSpanErrorStatusMinLevel is used.
+	SpanErrorStatusMinLevel slog.Leveler
+
+	// SpanRecordErrorMinLevel is the minimum level where the span will record
+	// the error as an exception event.
+	// If left nil, DefaultSpanRecordErrorMinLevel is used.
+	SpanRecordErrorMinLevel slog.Leveler
+
+	// SpanAddEventMinLevel is the minimum level where the span will add an
+	// event for the log line, if it is not already recording an error.
+	// If left nil, DefaultSpanAddEventMinLevel is used.
+	SpanAddEventMinLevel slog.Leveler
+
+	// DisableSpanErrorStatus stops the span status from ever being set.
+	DisableSpanErrorStatus bool
+
+	// DisableSpanRecordError stops errors from ever being recorded on the span.
+	DisableSpanRecordError bool
+
+	// DisableSpanAddEvent stops events from ever being added to the span.
+	DisableSpanAddEvent bool
+}
+
+// NewExtractor returns an AttrExtractor, same as ExtractTraceSpanID, but
+// configured by the options instead of the package level Default variables.
+// This allows differently configured extractors in the same binary, and the
+// Default variables can be left alone after the program starts.
+// Empty fields are taken from the Default variables when NewExtractor is called.
+func NewExtractor(opts Options) slogctx.AttrExtractor {
+	return opts.withDefaults().extract
+}
+
+// withDefaults returns a copy of the options with the empty fields set from
+// the package level Default variables.
+func (o Options) withDefaults() Options {
+	if o.KeyTraceID == "" {
+		o.KeyTraceID = DefaultKeyTraceID
+	}
+	if o.KeySpanID == "" {
+		o.KeySpanID = DefaultKeySpanID
+	}
+	if o.KeyTraceSampled == "" {
+		o.KeyTraceSampled = DefaultKeyTraceSampled
+	}
+	if o.SpanErrorStatusMinLevel == nil {
+		o.SpanErrorStatusMinLevel = DefaultSpanErrorStatusMinLevel
+	}
+	if o.SpanRecordErrorMinLevel == nil {
+		o.SpanRecordErrorMinLevel = DefaultSpanRecordErrorMinLevel
+	}
+	if o.SpanAddEventMinLevel == nil {
+		o.SpanAddEventMinLevel = DefaultSpanAddEventMinLevel
+	}
+	return o
+}
+
 // ExtractTraceSpanID is an AttrExtractor that returns any valid TraceID and
 // SpanID in any recording span.
 // In addition, if there is an error log being created inside a span, the span
 // is coded as an error, with the log message as the description.
 // The returned slice should not be appended to or modified in any way.
 // Doing so will cause a race condition.
-func ExtractTraceSpanID(ctx context.Context, _ time.Time, recordLvl slog.Level, recordMsg string) []slog.Attr {
-	if span := trace.SpanFromContext(ctx); span.IsRecording() {
-		if recordLvl >= DefaultSpanErrorStatusMinLevel {
-			span.SetStatus(codes.Error, recordMsg)
+//
+// It is configured by the package level Default variables.
+// Use NewExtractor for an extractor with its own configuration.
+func ExtractTraceSpanID(ctx context.Context, recordT time.Time, recordLvl slog.Level, recordMsg string) []slog.Attr {
+	return Options{}.withDefaults().extract(ctx, recordT, recordLvl, recordMsg)
+}
+
+func (o Options) extract(ctx context.Context, _ time.Time, recordLvl slog.Level, recordMsg string) []slog.Attr {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		if o.NonRecording {
+			return o.format(span.SpanContext())
 		}
-		if recordLvl >= DefaultSpanRecordErrorMinLevel {
-			span.RecordError(errors.New(recordMsg))
-		} else if recordLvl >= DefaultSpanAddEventMinLevel {
-			span.AddEvent(recordMsg)
+		return nil
+	}
+
+	// Start has already recorded the error on the span
+	if spanErrorRecorded(ctx) {
+		return o.format(span.SpanContext())
+	}
+
+	if !o.DisableSpanErrorStatus && recordLvl >= o.SpanErrorStatusMinLevel.Level() {
+		span.SetStatus(codes.Error, recordMsg)
+	}
+	if !o.DisableSpanRecordError && recordLvl >= o.SpanRecordErrorMinLevel.Level() {
+		span.RecordError(errors.New(recordMsg))
+	} else if !o.DisableSpanAddEvent && recordLvl >= o.SpanAddEventMinLevel.Level() {
+		span.AddEvent(recordMsg)
+	}
+	return o.format(span.SpanContext())
+}
+
+// format returns the attributes for the span context
+func (o Options) format(spanCtx trace.SpanContext) []slog.Attr {
+	if o.Formatter != nil {
+		if !spanCtx.IsValid() {
+			return nil
 		}
+		return o.Formatter(spanCtx)
+	}
 
-		var attrs []slog.Attr
-		spanCtx := span.SpanContext()
-		if spanCtx.HasTraceID() {
-			attrs = append(attrs, slog.String(DefaultKeyTraceID, spanCtx.TraceID().String()))
+	if o.NonRecording {
+		if !spanCtx.IsValid() {
+			return nil
 		}
-		if spanCtx.HasSpanID() {
-			attrs = append(attrs, slog.String(DefaultKeySpanID, spanCtx.SpanID().String()))
+		return []slog.Attr{
+			slog.String(o.KeyTraceID, spanCtx.TraceID().String()),
+			slog.String(o.KeySpanID, spanCtx.SpanID().String()),
+			slog.Bool(o.KeyTraceSampled, spanCtx.IsSampled()),
 		}
-		return attrs
 	}
-	return nil
+
+	var attrs []slog.Attr
+	if spanCtx.HasTraceID() {
+		attrs = append(attrs, slog.String(o.KeyTraceID, spanCtx.TraceID().String()))
+	}
+	if spanCtx.HasSpanID() {
+		attrs = append(attrs, slog.String(o.KeySpanID, spanCtx.SpanID().String()))
+	}
+	return attrs
 }
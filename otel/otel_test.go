@@ -123,6 +123,67 @@ func TestExtractTraceSpanIDAddEvent(t *testing.T) {
 	}
 }
 
+func TestNewExtractor(t *testing.T) {
+	tester := &testHandler{}
+	h := slogctx.NewHandler(
+		tester,
+		&slogctx.HandlerOptions{
+			Prependers: []slogctx.AttrExtractor{
+				NewExtractor(Options{
+					KeyTraceID:              "trace_id",
+					KeySpanID:               "span_id",
+					SpanErrorStatusMinLevel: slog.LevelWarn,
+					DisableSpanRecordError:  true,
+					DisableSpanAddEvent:     true,
+				}),
+			},
+		})
+	ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+	// Manually create the trace id and span id so the test is repeatable
+	traceID, err := trace.TraceIDFromHex(`0123456789abcdef0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(`0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Manually set the id's
+	span := &recorderSpan{
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}),
+	}
+
+	ctx = trace.ContextWithSpan(ctx, span)
+
+	slogctx.Info(ctx, "some info message")
+	if span.status != nil {
+		t.Errorf("Expected no status; Got: %v", *span.status)
+	}
+
+	slogctx.Error(ctx, "some error message")
+
+	expectedText := `time=2023-09-29T13:00:59.000Z level=ERROR msg="some error message" trace_id=0123456789abcdef0123456789abcdef span_id=0123456789abcdef
+`
+	if s := tester.String(); s != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", expectedText, s)
+	}
+
+	if span.status == nil || *span.status != codes.Error {
+		t.Errorf("Expected: %v; Got: %v", codes.Error, span.status)
+	}
+	if span.err != nil {
+		t.Errorf("Expected no error recorded; Got: %v", *span.err)
+	}
+	if span.event != nil {
+		t.Errorf("Expected no event added; Got: %v", *span.event)
+	}
+}
+
 // recorderSpan is an implementation of Span that performs no operations.
 type recorderSpan struct {
 	embedded.Span
@@ -131,6 +192,8 @@ type recorderSpan struct {
 	description *string
 	err         *error
 	event       *string
+	eventConfig trace.EventConfig
+	attrs       []attribute.KeyValue
 }
 
 var _ trace.Span = &recorderSpan{}
@@ -150,8 +213,10 @@ func (r *recorderSpan) SetStatus(c codes.Code, d string) {
 // SetError does nothing.
 func (*recorderSpan) SetError(bool) {}
 
-// SetAttributes does nothing.
-func (*recorderSpan) SetAttributes(...attribute.KeyValue) {}
+// SetAttributes records the attributes.
+func (r *recorderSpan) SetAttributes(kvs ...attribute.KeyValue) {
+	r.attrs = append(r.attrs, kvs...)
+}
 
 // End does nothing.
 func (*recorderSpan) End(...trace.SpanEndOption) {}
@@ -159,11 +224,13 @@ func (*recorderSpan) End(...trace.SpanEndOption) {}
 // RecordError records the error
 func (r *recorderSpan) RecordError(err error, opts ...trace.EventOption) {
 	r.err = &err
+	r.eventConfig = trace.NewEventConfig(opts...)
 }
 
 // AddEvent records the event
 func (r *recorderSpan) AddEvent(event string, opts ...trace.EventOption) {
 	r.event = &event
+	r.eventConfig = trace.NewEventConfig(opts...)
 }
 
 // SetName does nothing.
@@ -173,3 +240,50 @@ func (*recorderSpan) SetName(string) {}
 func (*recorderSpan) TracerProvider() trace.TracerProvider { return nil }
 
 func (r *recorderSpan) AddLink(link trace.Link) {}
+
+func TestNonRecording(t *testing.T) {
+	tester := &testHandler{}
+	h := slogctx.NewHandler(
+		tester,
+		&slogctx.HandlerOptions{
+			Prependers: []slogctx.AttrExtractor{
+				NewExtractor(Options{NonRecording: true}),
+			},
+		})
+	ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+	// Manually create the trace id and span id so the test is repeatable
+	traceID, err := trace.TraceIDFromHex(`0123456789abcdef0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(`0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A remote parent span context only, which is not recording and was sampled out
+	ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	}))
+
+	slogctx.Error(ctx, "main message")
+
+	expectedText := `time=2023-09-29T13:00:59.000Z level=ERROR msg="main message" TraceID=0123456789abcdef0123456789abcdef SpanID=0123456789abcdef TraceSampled=false
+`
+	if s := tester.String(); s != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", expectedText, s)
+	}
+
+	// Without the option, nothing is added
+	h = slogctx.NewHandler(tester, &slogctx.HandlerOptions{Prependers: []slogctx.AttrExtractor{ExtractTraceSpanID}})
+	slog.New(h).ErrorContext(ctx, "main message")
+
+	expectedText = `time=2023-09-29T13:00:59.000Z level=ERROR msg="main message"
+`
+	if s := tester.String(); s != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", expectedText, s)
+	}
+}
@@ -0,0 +1,184 @@
+package slogotel
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplingOptions are options for a SamplingHandler
+type SamplingOptions struct {
+	// Rates are the fractions (0 to 1) of requests whose logs are kept, per
+	// level. A record uses the rate of the highest level in the map that is
+	// at or below its own level, such as {slog.LevelDebug: 0.01, slog.LevelInfo: 0.1}.
+	// Records below all the levels in the map use the rate of the lowest level,
+	// so {slog.LevelInfo: 0.1} keeps the debug records of 10% of requests too.
+	// If left empty, all records are kept.
+	Rates map[slog.Level]float64
+
+	// KeepMinLevel is the minimum level that is always kept, regardless of Rates.
+	// If left nil, slog.LevelWarn is used.
+	KeepMinLevel slog.Leveler
+
+	// RequestID returns the id of the request, for records without a trace id
+	// in their context, such as sloghttp.RequestIDFromCtx.
+	// Records with neither a trace id or a request id are always kept.
+	RequestID func(ctx context.Context) string
+}
+
+// SamplingHandler is a slog.Handler middleware that keeps or drops all the log
+// records of a request together, so that sampled logs are complete.
+//
+// The decision comes from the OpenTelemetry trace id in the record's context,
+// in the same way as the otel TraceIDRatioBased sampler, so that all services
+// (and traces) sampling at the same rate keep the logs of the same requests.
+// If there is no trace id, the decision comes from a hash of the request id.
+type SamplingHandler struct {
+	next      slog.Handler
+	levels    []slog.Level // Sorted highest first
+	rates     []uint64     // Thresholds of the levels
+	keepMin   slog.Leveler
+	requestID func(ctx context.Context) string
+}
+
+var _ slog.Handler = &SamplingHandler{} // Assert conformance with interface
+
+// NewSamplingMiddleware creates a slogotel.SamplingHandler slog.Handler middleware
+// that conforms to [github.com/samber/slog-multi.Middleware] interface.
+// It can be used with slogmulti methods such as Pipe to easily setup a pipeline of slog handlers:
+//
+//	slog.SetDefault(slog.New(slogmulti.
+//		Pipe(slogotel.NewSamplingMiddleware(&slogotel.SamplingOptions{})).
+//		Pipe(slogctx.NewMiddleware(&slogctx.HandlerOptions{})).
+//		Handler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{})),
+//	))
+func NewSamplingMiddleware(options *SamplingOptions) func(slog.Handler) slog.Handler {
+	return func(next slog.Handler) slog.Handler {
+		return NewSamplingHandler(
+			next,
+			options,
+		)
+	}
+}
+
+// NewSamplingHandler creates a SamplingHandler slog.Handler middleware that
+// samples the log records of requests.
+// If opts is nil, the default options are used, which keep all records.
+func NewSamplingHandler(next slog.Handler, opts *SamplingOptions) *SamplingHandler {
+	o := SamplingOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.KeepMinLevel == nil {
+		o.KeepMinLevel = slog.LevelWarn
+	}
+
+	h := &SamplingHandler{
+		next:      next,
+		keepMin:   o.KeepMinLevel,
+		requestID: o.RequestID,
+	}
+	for level := range o.Rates {
+		h.levels = append(h.levels, level)
+	}
+	slices.Sort(h.levels)
+	slices.Reverse(h.levels)
+	for _, level := range h.levels {
+		h.rates = append(h.rates, threshold(o.Rates[level]))
+	}
+	return h
+}
+
+// Enabled reports whether the record would be kept by the sampling, and
+// whether the next handler handles records at the given level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.keep(ctx, level) && h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the next handler if it is kept by the sampling.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.keep(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithGroup returns a new SamplingHandler that still has h's attributes,
+// but any future attributes added will be namespaced.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	return &h2
+}
+
+// WithAttrs returns a new SamplingHandler whose attributes consists of h's attributes followed by attrs.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	return &h2
+}
+
+// keep returns true if records at the level are kept for the request in the context
+func (h *SamplingHandler) keep(ctx context.Context, level slog.Level) bool {
+	if level >= h.keepMin.Level() || ctx == nil {
+		return true
+	}
+	if len(h.levels) == 0 {
+		return true
+	}
+	i := slices.IndexFunc(h.levels, func(l slog.Level) bool { return l <= level })
+	if i < 0 {
+		// Below all the levels, so use the rate of the lowest one
+		i = len(h.levels) - 1
+	}
+	id, ok := h.id(ctx)
+	if !ok {
+		return true
+	}
+	return id < h.rates[i]
+}
+
+// id returns the 63 bit sampling id of the request in the context
+func (h *SamplingHandler) id(ctx context.Context) (uint64, bool) {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		// Same as the otel TraceIDRatioBased sampler
+		traceID := spanCtx.TraceID()
+		return binary.BigEndian.Uint64(traceID[8:16]) >> 1, true
+	}
+	if h.requestID != nil {
+		if reqID := h.requestID(ctx); reqID != "" {
+			hash := fnv.New64a()
+			_, _ = hash.Write([]byte(reqID))
+			return mix(hash.Sum64()) >> 1, true
+		}
+	}
+	return 0, false
+}
+
+// mix spreads the bits of the fnv hash, whose high bits barely change between
+// similar ids (such as sequential ones). It is the murmur3 64 bit finalizer.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// threshold returns the sampling id upper bound for the rate, the same as
+// the otel TraceIDRatioBased sampler.
+func threshold(rate float64) uint64 {
+	if rate >= 1 {
+		return math.MaxUint64
+	}
+	if rate <= 0 {
+		return 0
+	}
+	return uint64(rate * (1 << 63))
+}
@@ -0,0 +1,116 @@
+package slogotel
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func traceCtx(t *testing.T, hex string) context.Context {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{1},
+	}))
+}
+
+type requestIDKey struct{}
+
+func TestSamplingHandler(t *testing.T) {
+	tester := &testHandler{}
+	h := NewSamplingHandler(tester, &SamplingOptions{
+		Rates: map[slog.Level]float64{slog.LevelDebug: 0, slog.LevelInfo: 0.5},
+		RequestID: func(ctx context.Context) string {
+			id, _ := ctx.Value(requestIDKey{}).(string)
+			return id
+		},
+	})
+
+	low := traceCtx(t, "0123456789abcdef0000000000000001")
+	high := traceCtx(t, "0123456789abcdefffffffffffffffff")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		level    slog.Level
+		expected bool
+	}{
+		{name: "low info", ctx: low, level: slog.LevelInfo, expected: true},
+		{name: "low info+1", ctx: low, level: slog.LevelInfo + 1, expected: true},
+		{name: "low debug", ctx: low, level: slog.LevelDebug, expected: false},
+		{name: "low below debug", ctx: low, level: slog.LevelDebug - 1, expected: false},
+		{name: "high info", ctx: high, level: slog.LevelInfo, expected: false},
+		{name: "high warn", ctx: high, level: slog.LevelWarn, expected: true},
+		{name: "high error", ctx: high, level: slog.LevelError, expected: true},
+		{name: "no id", ctx: context.Background(), level: slog.LevelDebug, expected: true},
+		{name: "request id debug", ctx: context.WithValue(context.Background(), requestIDKey{}, "abc"), level: slog.LevelDebug, expected: false},
+	}
+	for _, tc := range tests {
+		if got := h.Enabled(tc.ctx, tc.level); got != tc.expected {
+			t.Error(tc.name, "Expected:", tc.expected, "Got:", got)
+		}
+
+		tester.Record = slog.Record{}
+		if err := h.Handle(tc.ctx, slog.NewRecord(defaultTime, tc.level, tc.name, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if got := tester.Record.Message == tc.name; got != tc.expected {
+			t.Error(tc.name, "Expected handled:", tc.expected, "Got:", got)
+		}
+	}
+
+	// Request ids are sampled consistently, about half at a rate of 0.5
+	var kept int
+	for i := 0; i < 1000; i++ {
+		ctx := context.WithValue(context.Background(), requestIDKey{}, fmt.Sprintf("request-%d", i))
+		first := h.Enabled(ctx, slog.LevelInfo)
+		if first != h.Enabled(ctx, slog.LevelInfo) {
+			t.Fatal("Expected a consistent decision for the same request id")
+		}
+		if first {
+			kept++
+		}
+	}
+	if kept < 400 || kept > 600 {
+		t.Error("Expected about 500 kept; Got:", kept)
+	}
+
+	// Levels below all the rates are sampled at the rate of the lowest level
+	h = NewSamplingHandler(tester, &SamplingOptions{Rates: map[slog.Level]float64{slog.LevelInfo: 0.5}})
+	if !h.Enabled(low, slog.LevelDebug) || h.Enabled(high, slog.LevelDebug) {
+		t.Error("Expected debug to be sampled at the info rate")
+	}
+
+	// Without any rates, all records are kept
+	h = NewSamplingHandler(tester, nil)
+	if !h.Enabled(high, slog.LevelDebug) {
+		t.Error("Expected all records to be kept")
+	}
+}
+
+func TestSamplingMatchesTraceIDRatioBased(t *testing.T) {
+	const rate = 0.3
+	sampler := sdktrace.TraceIDRatioBased(rate)
+	h := NewSamplingHandler(&testHandler{}, &SamplingOptions{Rates: map[slog.Level]float64{slog.LevelDebug: rate}})
+
+	for i := uint64(0); i < 1000; i++ {
+		var traceID trace.TraceID
+		binary.BigEndian.PutUint64(traceID[:8], i+1)
+		binary.BigEndian.PutUint64(traceID[8:], i*0x9e3779b97f4a7c15) // Spread across the id space
+		ctx := traceCtx(t, traceID.String())
+
+		expected := sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID}).Decision == sdktrace.RecordAndSample
+		if got := h.Enabled(ctx, slog.LevelInfo); got != expected {
+			t.Error("Trace:", traceID, "Expected:", expected, "Got:", got)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package slogotel
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"github.com/veqryn/slog-context/propagate"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultSpanAttrsMax is the default maximum number of attributes that SpanAttrs
+// will set on a span.
+const DefaultSpanAttrsMax = 32
+
+// DefaultSpanAttrsMaxValueLength is the default maximum length of a string
+// attribute value that SpanAttrs will set on a span.
+const DefaultSpanAttrsMaxValueLength = 256
+
+// SpanAttrsOptions are options for NewSpanAttrs
+type SpanAttrsOptions struct {
+	// Keys are the attribute keys that are promoted to span attributes, such
+	// as "user_id" and "order_id". A key that is a group promotes all of the
+	// attributes inside of it, as dotted keys (ex: "user.id").
+	// If left nil, no attributes are promoted.
+	Keys []string
+
+	// MaxAttrs guards against adding a large number of attributes to a span.
+	// It counts the distinct keys set on each span, across all calls that use
+	// the context returned by the previous call. Attributes with new keys past
+	// this limit are not promoted.
+	// If left zero, DefaultSpanAttrsMax is used. Negative is unlimited.
+	MaxAttrs int
+
+	// MaxValueLength guards against adding large strings, such as request
+	// bodies, to a span. Longer string values are not promoted.
+	// If left zero, DefaultSpanAttrsMaxValueLength is used. Negative is unlimited.
+	MaxValueLength int
+}
+
+// SpanAttrs promotes log attributes stored in the context to attributes on
+// the current recording span, so that traces and logs share the same
+// dimensions without every call site adding them to both.
+//
+// Attributes can be promoted when they are added, by using the Prepend,
+// Append, and With methods in place of slogctx.Prepend, slogctx.Append, and
+// propagate.With, or all at once when the span ends, by calling Promote.
+// Always use the returned context, because it tracks the keys already set on
+// the span.
+type SpanAttrs struct {
+	keys           []string
+	maxAttrs       int
+	maxValueLength int
+}
+
+// NewSpanAttrs returns a SpanAttrs that promotes the context attributes
+// allowed by the options.
+func NewSpanAttrs(opts SpanAttrsOptions) *SpanAttrs {
+	if opts.MaxAttrs == 0 {
+		opts.MaxAttrs = DefaultSpanAttrsMax
+	}
+	if opts.MaxValueLength == 0 {
+		opts.MaxValueLength = DefaultSpanAttrsMaxValueLength
+	}
+	return &SpanAttrs{
+		keys:           slices.Clone(opts.Keys),
+		maxAttrs:       opts.MaxAttrs,
+		maxValueLength: opts.MaxValueLength,
+	}
+}
+
+// Prepend is the same as slogctx.Prepend, but also sets the attributes on
+// the recording span in the context.
+func (s *SpanAttrs) Prepend(parent context.Context, args ...any) context.Context {
+	return slogctx.Prepend(s.set(parent, argsToAttrs(args)), args...)
+}
+
+// Append is the same as slogctx.Append, but also sets the attributes on
+// the recording span in the context.
+func (s *SpanAttrs) Append(parent context.Context, args ...any) context.Context {
+	return slogctx.Append(s.set(parent, argsToAttrs(args)), args...)
+}
+
+// With is the same as propagate.With, but also sets the attributes on
+// the recording span in the context. Unlike propagate.With, the returned
+// context may be a new one.
+func (s *SpanAttrs) With(ctx context.Context, args ...any) context.Context {
+	return propagate.With(s.set(ctx, argsToAttrs(args)), args...)
+}
+
+// Promote sets all the attributes in the context, that were added with
+// slogctx.Prepend, slogctx.Append, or propagate.With, on the recording span
+// in the context. Call it just before ending the span:
+//
+//	defer span.End()
+//	defer spanAttrs.Promote(ctx)
+func (s *SpanAttrs) Promote(ctx context.Context) {
+	var attrs []slog.Attr
+	for _, f := range []slogctx.AttrExtractor{slogctx.ExtractPrepended, propagate.ExtractAttrs, slogctx.ExtractAppended} {
+		attrs = append(attrs, f(ctx, time.Time{}, slog.LevelInfo, "")...)
+	}
+	s.set(ctx, attrs)
+}
+
+// set sets the allowed attributes on the recording span in the context.
+// It returns a context that tracks the keys set on the span.
+func (s *SpanAttrs) set(ctx context.Context, attrs []slog.Attr) context.Context {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() || len(attrs) == 0 || len(s.keys) == 0 {
+		return ctx
+	}
+
+	attrs = slices.DeleteFunc(slices.Clone(attrs), func(a slog.Attr) bool {
+		return !slices.Contains(s.keys, a.Key)
+	})
+
+	kvs := appendKeyValues(nil, "", attrs...)
+	kvs = slices.DeleteFunc(kvs, func(kv attribute.KeyValue) bool {
+		return s.maxValueLength > 0 && kv.Value.Type() == attribute.STRING && len(kv.Value.AsString()) > s.maxValueLength
+	})
+	if len(kvs) == 0 {
+		return ctx
+	}
+
+	ctx, promoted := promotedKeysFromCtx(ctx, span.SpanContext())
+	kvs = promoted.allow(kvs, s.maxAttrs)
+	if len(kvs) > 0 {
+		span.SetAttributes(kvs...)
+	}
+	return ctx
+}
+
+// promotedKeys are the keys already set on a span by SpanAttrs
+type promotedKeys struct {
+	sc   trace.SpanContext
+	mu   sync.Mutex
+	keys map[attribute.Key]struct{}
+}
+
+// promotedKeysKey is the context key for the *promotedKeys
+type promotedKeysKey struct{}
+
+// promotedKeysFromCtx returns the keys set on the span, and a context with
+// them. A span without any is given a new set, such as a child span started
+// from a context that tracked its parent.
+func promotedKeysFromCtx(ctx context.Context, sc trace.SpanContext) (context.Context, *promotedKeys) {
+	if p, ok := ctx.Value(promotedKeysKey{}).(*promotedKeys); ok && p.sc.Equal(sc) {
+		return ctx, p
+	}
+	p := &promotedKeys{sc: sc, keys: map[attribute.Key]struct{}{}}
+	return context.WithValue(ctx, promotedKeysKey{}, p), p
+}
+
+// allow removes the attributes with new keys past the maximum (if greater
+// than zero), and records the rest as set on the span. Keys already set can
+// be set again, because they replace the old value.
+func (p *promotedKeys) allow(kvs []attribute.KeyValue, maxAttrs int) []attribute.KeyValue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.DeleteFunc(kvs, func(kv attribute.KeyValue) bool {
+		if _, ok := p.keys[kv.Key]; ok {
+			return false
+		}
+		if maxAttrs > 0 && len(p.keys) >= maxAttrs {
+			return true
+		}
+		p.keys[kv.Key] = struct{}{}
+		return false
+	})
+}
+
+// argsToAttrs turns alternating key-value pairs and slog.Attr's into
+// attributes, the same way as slog.Logger.With.
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,105 @@
+package slogotel
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/veqryn/slog-context/propagate"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanAttrs(t *testing.T) {
+	// Manually create the trace id and span id so the test is repeatable
+	traceID, err := trace.TraceIDFromHex(`0123456789abcdef0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(`0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	span := &recorderSpan{
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}),
+	}
+	ctx := trace.ContextWithSpan(propagate.Init(context.Background()), span)
+
+	spanAttrs := NewSpanAttrs(SpanAttrsOptions{
+		Keys:           []string{"user_id", "order_id", "user", "body"},
+		MaxValueLength: 10,
+	})
+
+	// Promoted when added
+	ctx = spanAttrs.Prepend(ctx, "user_id", "u1", "password", "hunter2")
+	ctx = spanAttrs.Append(ctx, "body", strings.Repeat("x", 11))
+	ctx = spanAttrs.With(ctx, "order_id", 12345)
+
+	expected := []attribute.KeyValue{
+		attribute.String("user_id", "u1"),
+		attribute.Int64("order_id", 12345),
+	}
+	if !reflect.DeepEqual(span.attrs, expected) {
+		t.Errorf("Expected: %v; Got: %v", expected, span.attrs)
+	}
+
+	// Promoted all at once, at span end
+	span.attrs = nil
+	spanAttrs.Promote(ctx)
+
+	expected = []attribute.KeyValue{
+		attribute.String("user_id", "u1"),
+		attribute.Int64("order_id", 12345),
+	}
+	if !reflect.DeepEqual(span.attrs, expected) {
+		t.Errorf("Expected: %v; Got: %v", expected, span.attrs)
+	}
+
+	// The cardinality guard limits the number of attributes on the span,
+	// across calls, while keys already set can be set again
+	span.attrs = nil
+	limited := NewSpanAttrs(SpanAttrsOptions{Keys: []string{"a", "b", "c"}, MaxAttrs: 2})
+	ctx = limited.Prepend(trace.ContextWithSpan(context.Background(), span), "a", 1, "b", 2)
+	ctx = limited.Append(ctx, "c", 3)
+	limited.With(ctx, "a", 4)
+
+	expected = []attribute.KeyValue{
+		attribute.Int64("a", 1),
+		attribute.Int64("b", 2),
+		attribute.Int64("a", 4),
+	}
+	if !reflect.DeepEqual(span.attrs, expected) {
+		t.Errorf("Expected: %v; Got: %v", expected, span.attrs)
+	}
+
+	// A child span has its own limit
+	childSpanID, err := trace.SpanIDFromHex(`0123456789abcdee`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := &recorderSpan{
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  childSpanID,
+		}),
+	}
+	limited.Append(trace.ContextWithSpan(ctx, child), "c", 3)
+
+	expected = []attribute.KeyValue{
+		attribute.Int64("c", 3),
+	}
+	if !reflect.DeepEqual(child.attrs, expected) {
+		t.Errorf("Expected: %v; Got: %v", expected, child.attrs)
+	}
+
+	// Without any keys, nothing is promoted
+	span.attrs = nil
+	NewSpanAttrs(SpanAttrsOptions{}).Prepend(ctx, "a", 1)
+	if len(span.attrs) != 0 {
+		t.Error("Expected no attributes; Got:", span.attrs)
+	}
+}
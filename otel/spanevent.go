@@ -0,0 +1,194 @@
+package slogotel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanEventHandlerOptions are options for a SpanEventHandler
+type SpanEventHandlerOptions struct {
+	// SpanErrorStatusMinLevel is the minimum level where the span will be set
+	// to a status of otel codes.Error, with the log message as the description.
+	// If left nil, DefaultSpanErrorStatusMinLevel is used.
+	SpanErrorStatusMinLevel slog.Leveler
+
+	// SpanRecordErrorMinLevel is the minimum level where the span will record
+	// the error as an exception event.
+	// If left nil, DefaultSpanRecordErrorMinLevel is used.
+	SpanRecordErrorMinLevel slog.Leveler
+
+	// SpanAddEventMinLevel is the minimum level where the span will add an
+	// event for the log line, if it is not already recording an error.
+	// If left nil, DefaultSpanAddEventMinLevel is used.
+	SpanAddEventMinLevel slog.Leveler
+}
+
+// SpanEventHandler is a slog.Handler middleware that annotates the recording
+// span in the log record's context, the same as ExtractTraceSpanID, except the
+// span events carry all the attributes of the log record, not just the message.
+// Groups are flattened into dotted keys, such as "group1.key1".
+//
+// If the record has an error attribute under the slogctx.ErrKey key, such as
+// one added with slogctx.Err, that error is recorded on the span (with its type
+// and a stack trace), instead of an error made from the log message. The error
+// attribute may be inside of a group.
+//
+// To include the context attributes, put it after the slogctx.Handler in the
+// chain, and turn off the span annotations of the extractor, so that they are
+// not done twice:
+//
+//	slogotel.NewSpanEventHandler(slog.NewJSONHandler(os.Stdout, nil), nil)
+//	slogotel.NewExtractor(slogotel.Options{DisableSpanErrorStatus: true, DisableSpanRecordError: true, DisableSpanAddEvent: true})
+//
+// It passes the record off to the next handler unchanged.
+type SpanEventHandler struct {
+	next   slog.Handler
+	opts   SpanEventHandlerOptions
+	attrs  []attribute.KeyValue
+	prefix string
+}
+
+var _ slog.Handler = &SpanEventHandler{} // Assert conformance with interface
+
+// NewSpanEventMiddleware creates a slogotel.SpanEventHandler slog.Handler middleware
+// that conforms to [github.com/samber/slog-multi.Middleware] interface.
+// It can be used with slogmulti methods such as Pipe to easily setup a pipeline of slog handlers:
+//
+//	slog.SetDefault(slog.New(slogmulti.
+//		Pipe(slogctx.NewMiddleware(&slogctx.HandlerOptions{})).
+//		Pipe(slogotel.NewSpanEventMiddleware(&slogotel.SpanEventHandlerOptions{})).
+//		Handler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{})),
+//	))
+func NewSpanEventMiddleware(options *SpanEventHandlerOptions) func(slog.Handler) slog.Handler {
+	return func(next slog.Handler) slog.Handler {
+		return NewSpanEventHandler(
+			next,
+			options,
+		)
+	}
+}
+
+// NewSpanEventHandler creates a SpanEventHandler slog.Handler middleware that
+// adds the log records as events to the recording span in the context.
+// If opts is nil, the default options are used.
+func NewSpanEventHandler(next slog.Handler, opts *SpanEventHandlerOptions) *SpanEventHandler {
+	o := SpanEventHandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.SpanErrorStatusMinLevel == nil {
+		o.SpanErrorStatusMinLevel = DefaultSpanErrorStatusMinLevel
+	}
+	if o.SpanRecordErrorMinLevel == nil {
+		o.SpanRecordErrorMinLevel = DefaultSpanRecordErrorMinLevel
+	}
+	if o.SpanAddEventMinLevel == nil {
+		o.SpanAddEventMinLevel = DefaultSpanAddEventMinLevel
+	}
+
+	return &SpanEventHandler{
+		next: next,
+		opts: o,
+	}
+}
+
+// Enabled reports whether the next handler handles records at the given level.
+// The handler ignores records whose level is lower.
+func (h *SpanEventHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle annotates the recording span in the context with the record, then
+// passes the record to the next handler.
+func (h *SpanEventHandler) Handle(ctx context.Context, r slog.Record) error {
+	// Start has already recorded the error on the span
+	if span := trace.SpanFromContext(ctx); span.IsRecording() && !spanErrorRecorded(ctx) {
+		h.annotate(span, r)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// annotate sets the status of the span and adds the record as an event
+func (h *SpanEventHandler) annotate(span trace.Span, r slog.Record) {
+	errorStatus := r.Level >= h.opts.SpanErrorStatusMinLevel.Level()
+	recordError := r.Level >= h.opts.SpanRecordErrorMinLevel.Level()
+	addEvent := r.Level >= h.opts.SpanAddEventMinLevel.Level()
+	if !errorStatus && !recordError && !addEvent {
+		return
+	}
+
+	if errorStatus {
+		span.SetStatus(codes.Error, r.Message)
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(h.attrs)+r.NumAttrs())
+	kvs = append(kvs, h.attrs...)
+	var err error
+	r.Attrs(func(a slog.Attr) bool {
+		if err == nil {
+			err = findErr(a)
+		}
+		kvs = appendKeyValues(kvs, h.prefix, a)
+		return true
+	})
+
+	if recordError {
+		if err == nil {
+			err = errors.New(r.Message)
+		}
+		span.RecordError(err, trace.WithAttributes(kvs...), trace.WithStackTrace(true))
+	} else if addEvent {
+		span.AddEvent(r.Message, trace.WithAttributes(kvs...))
+	}
+}
+
+// findErr returns the first error under the slogctx.ErrKey key, which may be
+// inside of a group when the logger had a group.
+func findErr(a slog.Attr) error {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		for _, ga := range v.Group() {
+			if err := findErr(ga); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err, ok := v.Any().(error); ok && a.Key == slogctx.ErrKey {
+		return err
+	}
+	return nil
+}
+
+// WithGroup returns a new SpanEventHandler that still has h's attributes,
+// but any future attributes added will be namespaced.
+func (h *SpanEventHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	if h2.prefix == "" {
+		h2.prefix = name
+	} else {
+		h2.prefix = h.prefix + "." + name
+	}
+	return &h2
+}
+
+// WithAttrs returns a new SpanEventHandler whose attributes consists of h's attributes followed by attrs.
+func (h *SpanEventHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = appendKeyValues(h.attrs[:len(h.attrs):len(h.attrs)], h.prefix, attrs...)
+	return &h2
+}
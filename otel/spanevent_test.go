@@ -0,0 +1,103 @@
+package slogotel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
+
+func TestSpanEventHandler(t *testing.T) {
+	tester := &testHandler{}
+	h := slogctx.NewHandler(
+		NewSpanEventHandler(tester, &SpanEventHandlerOptions{SpanAddEventMinLevel: slog.LevelInfo}),
+		nil,
+	)
+	ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+	// Manually create the trace id and span id so the test is repeatable
+	traceID, err := trace.TraceIDFromHex(`0123456789abcdef0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex(`0123456789abcdef`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span := &recorderSpan{
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}),
+	}
+	ctx = trace.ContextWithSpan(ctx, span)
+	ctx = slogctx.Prepend(ctx, "prepend1", "arg1")
+	ctx = slogctx.Append(ctx, "append1", 1)
+
+	// An event with all the attributes
+	slogctx.Info(ctx, "some info message", "main1", "arg1", slog.Group("group1", "num", 2, slog.Group("group2", "ok", true)))
+
+	if span.event == nil || *span.event != "some info message" {
+		t.Errorf("Expected: %v; Got: %v", "some info message", span.event)
+	}
+	expected := []attribute.KeyValue{
+		attribute.String("prepend1", "arg1"),
+		attribute.String("main1", "arg1"),
+		attribute.Int64("group1.num", 2),
+		attribute.Bool("group1.group2.ok", true),
+		attribute.Int64("append1", 1),
+	}
+	if got := span.eventConfig.Attributes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected: %v; Got: %v", expected, got)
+	}
+	if span.status != nil || span.err != nil {
+		t.Error("Expected no error status or recorded error")
+	}
+
+	// The actual error is recorded, with a stack trace
+	logErr := &testError{msg: "database is down"}
+	slog.New(h).With("with1", "arg1").WithGroup("g").ErrorContext(ctx, "query failed", slogctx.Err(logErr))
+
+	if span.status == nil || *span.status != codes.Error || *span.description != "query failed" {
+		t.Errorf("Expected: %v %v; Got: %v %v", codes.Error, "query failed", span.status, span.description)
+	}
+	if span.err == nil || !errors.Is(*span.err, logErr) {
+		t.Errorf("Expected: %v; Got: %v", logErr, span.err)
+	}
+	if !span.eventConfig.StackTrace() {
+		t.Error("Expected a stack trace")
+	}
+	expected = []attribute.KeyValue{
+		attribute.String("prepend1", "arg1"),
+		attribute.String("with1", "arg1"),
+		attribute.String("g.err", "database is down"),
+		attribute.Int64("g.append1", 1),
+	}
+	if got := span.eventConfig.Attributes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected: %v; Got: %v", expected, got)
+	}
+
+	// Without an error attribute, the error is made from the message
+	slogctx.Error(ctx, "no error attribute")
+	if span.err == nil || (*span.err).Error() != "no error attribute" {
+		t.Errorf("Expected: %v; Got: %v", "no error attribute", span.err)
+	}
+
+	// The log lines are passed on unchanged
+	expectedText := `time=2023-09-29T13:00:59.000Z level=ERROR msg="no error attribute" prepend1=arg1 append1=1
+`
+	if s := tester.String(); s != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", expectedText, s)
+	}
+}
@@ -0,0 +1,75 @@
+package slogotel
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultStartErrorLevel is the level that the end function returned by Start
+// logs the completion of the span at, when there is an error.
+var DefaultStartErrorLevel = slog.LevelError
+
+// Start starts a span with the tracer, adds the attributes to both the span
+// and the log context (with slogctx.Prepend), and logs the start of the span
+// at Debug level. The attributes are alternating key-value pairs and
+// slog.Attr's, the same as slogctx.Prepend.
+//
+// The returned end function must be called when the work is done, with its
+// error if any. It logs the completion with the duration, at Debug level, or
+// at DefaultStartErrorLevel if the error is not nil. Then it ends the span,
+// after setting the span status and recording the error if it is not nil:
+//
+//	ctx, end := slogotel.Start(ctx, tracer, "fetchUser", "user_id", id)
+//	defer func() { end(err) }()
+//
+// With ExtractTraceSpanID (or NewExtractor) configured on the slogctx.Handler,
+// all log lines using the returned context carry the id of the new span.
+// The extractor and SpanEventHandler would also set the status and record an
+// error on the span for an error level log line, with the log message
+// "spanEnd". They do not do so for the completion line, because the end
+// function records the error itself.
+func Start(ctx context.Context, tracer trace.Tracer, name string, args ...any) (context.Context, func(error)) {
+	attrs := argsToAttrs(args)
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(appendKeyValues(nil, "", attrs...)...))
+	if len(args) > 0 {
+		ctx = slogctx.Prepend(ctx, args...)
+	}
+
+	slogctx.Debug(ctx, "spanStart", "span_name", name)
+	before := time.Now()
+
+	return ctx, func(err error) {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsed := float64(time.Since(before)) / float64(time.Millisecond)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			slogctx.Log(withSpanErrorRecorded(ctx), DefaultStartErrorLevel, "spanEnd", "span_name", name, "ms", elapsed, slogctx.Err(err))
+		} else {
+			slogctx.Debug(ctx, "spanEnd", "span_name", name, "ms", elapsed)
+		}
+		span.End()
+	}
+}
+
+// spanErrorRecordedKey is the context key marking that the error of the log
+// line has already been recorded on the span
+type spanErrorRecordedKey struct{}
+
+// withSpanErrorRecorded returns a context that stops the extractor from
+// setting the status, recording an error, or adding an event on the span.
+func withSpanErrorRecorded(ctx context.Context) context.Context {
+	return context.WithValue(ctx, spanErrorRecordedKey{}, true)
+}
+
+// spanErrorRecorded reports if the error of the log line has already been
+// recorded on the span
+func spanErrorRecorded(ctx context.Context) bool {
+	recorded, _ := ctx.Value(spanErrorRecordedKey{}).(bool)
+	return recorded
+}
@@ -0,0 +1,124 @@
+package slogotel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStart(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := slogctx.NewHandler(
+		slog.NewJSONHandler(buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey || a.Key == "ms" || a.Key == DefaultKeyTraceID || a.Key == DefaultKeySpanID {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		&slogctx.HandlerOptions{
+			Prependers: []slogctx.AttrExtractor{
+				ExtractTraceSpanID,
+				slogctx.ExtractPrepended,
+			},
+		})
+	ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ctx, end := Start(ctx, tracer, "fetchUser", "user_id", "u1")
+	slogctx.Info(ctx, "in span")
+	end(errors.New("user not found"))
+
+	expected := `{"level":"DEBUG","msg":"spanStart","user_id":"u1","span_name":"fetchUser"}
+{"level":"INFO","msg":"in span","user_id":"u1"}
+{"level":"ERROR","msg":"spanEnd","user_id":"u1","span_name":"fetchUser","err":"user not found"}
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s\n", expected, buf.String())
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatal("Expected 1 span; Got:", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "fetchUser" {
+		t.Error("Expected: fetchUser; Got:", span.Name())
+	}
+	if attrs := span.Attributes(); len(attrs) != 1 || attrs[0] != attribute.String("user_id", "u1") {
+		t.Error("Expected: user_id=u1; Got:", attrs)
+	}
+	if span.Status().Code != codes.Error || span.Status().Description != "user not found" {
+		t.Error("Expected: Error user not found; Got:", span.Status())
+	}
+	// Recorded once, by the end function, and not again by the extractor for the error log line
+	if events := span.Events(); len(events) != 1 || events[0].Name != "exception" ||
+		!slices.Contains(events[0].Attributes, attribute.String("exception.message", "user not found")) {
+		t.Error("Expected the error to be recorded once; Got:", events)
+	}
+
+	// The log lines in the span carry the id of the new span
+	tester := &testHandler{}
+	ctx = slogctx.NewCtx(ctx, slog.New(slogctx.NewHandler(tester, &slogctx.HandlerOptions{
+		Prependers: []slogctx.AttrExtractor{ExtractTraceSpanID},
+	})))
+	ctx, end = Start(ctx, tracer, "child")
+	spanID := trace.SpanFromContext(ctx).SpanContext().SpanID().String()
+	end(nil)
+
+	var got string
+	tester.Record.Attrs(func(a slog.Attr) bool {
+		if a.Key == DefaultKeySpanID {
+			got = a.Value.String()
+		}
+		return true
+	})
+	if tester.Record.Message != "spanEnd" || got != spanID {
+		t.Error("Expected:", spanID, "Got:", tester.Record.Message, got)
+	}
+	if status := recorder.Ended()[1].Status(); status.Code != codes.Unset {
+		t.Error("Expected: Unset; Got:", status)
+	}
+}
+
+func TestStartSpanEventHandler(t *testing.T) {
+	// The setup recommended by SpanEventHandler
+	h := slogctx.NewHandler(
+		NewSpanEventHandler(slog.NewJSONHandler(io.Discard, nil), nil),
+		&slogctx.HandlerOptions{
+			Prependers: []slogctx.AttrExtractor{
+				NewExtractor(Options{DisableSpanErrorStatus: true, DisableSpanRecordError: true, DisableSpanAddEvent: true}),
+			},
+		})
+	ctx := slogctx.NewCtx(context.Background(), slog.New(h))
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, end := Start(ctx, tracer, "query")
+	end(errors.New("db down"))
+
+	span := recorder.Ended()[0]
+	if span.Status().Code != codes.Error || span.Status().Description != "db down" {
+		t.Error("Expected: Error db down; Got:", span.Status())
+	}
+	if events := span.Events(); len(events) != 1 ||
+		!slices.Contains(events[0].Attributes, attribute.String("exception.message", "db down")) {
+		t.Error("Expected the error to be recorded once; Got:", events)
+	}
+}
@@ -28,6 +28,12 @@ func Init(parent context.Context) context.Context {
 	return context.WithValue(parent, ctxKey{}, &syncAttrs{})
 }
 
+// Initialized returns true if propagation was initialized on the context,
+// either by Init or by With.
+func Initialized(ctx context.Context) bool {
+	return fromCtx(ctx) != nil
+}
+
 // With adds the provided attributes to the context and propagates them to parent contextes.
 // If propagation wasn't initialized on the context via a Init(), it will initialize at this point,
 // followed by adding the attributes to it.
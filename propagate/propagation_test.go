@@ -197,3 +197,15 @@ func middlewareWithInitPropagation(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+func TestInitialized(t *testing.T) {
+	if Initialized(nil) || Initialized(context.Background()) {
+		t.Error("Expected propagation to not be initialized")
+	}
+	if !Initialized(Init(context.Background())) {
+		t.Error("Expected propagation to be initialized by Init")
+	}
+	if !Initialized(With(context.Background(), "id", "13579")) {
+		t.Error("Expected propagation to be initialized by With")
+	}
+}